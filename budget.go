@@ -0,0 +1,402 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BudgetLine compares one tag's spending with its monthly budget.
+type BudgetLine struct {
+	Tag        string
+	Monthly    float64
+	Actual     float64 // absolute expense total
+	FullTarget float64 // Monthly × calendar months touched
+	Target     float64 // FullTarget, or the prorated target with --prorate
+}
+
+// budgetMonths returns how many calendar months the range [start, end]
+// touches, and the same span counted in fractions of months by days covered.
+func budgetMonths(start, end time.Time) (full int, prorated float64) {
+	for m := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(end); m = m.AddDate(0, 1, 0) {
+		next := m.AddDate(0, 1, 0)
+		from, to := m, next.AddDate(0, 0, -1)
+		if start.After(from) {
+			from = start
+		}
+		if end.Before(to) {
+			to = end
+		}
+		days := to.Sub(from).Hours()/24 + 1
+		full++
+		prorated += days / (next.Sub(m).Hours() / 24)
+	}
+	return full, prorated
+}
+
+// budgetRange is the period a budget comparison covers: --from/--to when
+// given, otherwise from the first of the earliest month to the latest
+// transaction.
+func budgetRange(transactions []Transaction) (start, end time.Time, ok bool) {
+	for i, txn := range transactions {
+		if i == 0 || txn.Date.Before(start) {
+			start = txn.Date
+		}
+		if i == 0 || txn.Date.After(end) {
+			end = txn.Date
+		}
+	}
+	if len(transactions) == 0 {
+		return start, end, false
+	}
+	start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if t, err := time.Parse("2006-01-02", fromDate); err == nil {
+		start = t
+	}
+	if t, err := time.Parse("2006-01-02", toDate); err == nil {
+		end = t
+	}
+	return start, end, true
+}
+
+func budgetVsActual(transactions []Transaction, budgets map[string]float64) []BudgetLine {
+	start, end, ok := budgetRange(transactions)
+	if !ok {
+		return nil
+	}
+	full, prorated := budgetMonths(start, end)
+
+	actual := map[string]float64{}
+	for tag, total := range tagTotals(transactions) {
+		actual[strings.ToLower(tag)] += total
+	}
+
+	var out []BudgetLine
+	for tag, monthly := range budgets {
+		line := BudgetLine{
+			Tag:        tag,
+			Monthly:    monthly,
+			Actual:     abs(min(actual[strings.ToLower(tag)], 0)),
+			FullTarget: monthly * float64(full),
+		}
+		line.Target = line.FullTarget
+		if prorate {
+			line.Target = monthly * prorated
+		}
+		out = append(out, line)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tag < out[j].Tag })
+	return out
+}
+
+func printBudget(transactions []Transaction, budgets map[string]float64) {
+	fmt.Println(glyph("🎯 ", "") + "Budget vs Actual:")
+	lines := budgetVsActual(transactions, budgets)
+	if len(lines) == 0 {
+		fmt.Println("  (no transactions)")
+	}
+	for _, l := range lines {
+		status := glyph("✅", "ok")
+		if l.Actual > l.Target {
+			status = glyph("❌", "OVER")
+		}
+		if prorate {
+			fmt.Printf("  [%s] %.2f of %.2f prorated (%.2f full-month)  %s\n", l.Tag, l.Actual, l.Target, l.FullTarget, status)
+		} else {
+			fmt.Printf("  [%s] %.2f of %.2f  %s\n", l.Tag, l.Actual, l.Target, status)
+		}
+	}
+	fmt.Println()
+}
+
+// printWeeklyAllowance checks spending on --allowance-tags (all expenses if
+// none are given) against a weekly allowance, one line per ISO week.
+func printWeeklyAllowance(transactions []Transaction, allowance float64, tags []string) {
+	fmt.Printf("%sWeekly Allowance (%.2f):\n", glyph("👛 ", ""), allowance)
+
+	tagSet := parseRemovals(strings.Join(tags, ","))
+	var matching []Transaction
+	for _, txn := range transactions {
+		if txn.Amount >= 0 {
+			continue
+		}
+		if len(tagSet) > 0 && !hasAnyTag(txn, tagSet) {
+			continue
+		}
+		matching = append(matching, txn)
+	}
+
+	groups := groupByPeriod(matching, "week")
+	if len(groups) == 0 {
+		fmt.Println("  (no matching expenses)")
+	}
+	for _, week := range sortedPeriods(groups) {
+		_, spent := totalAmounts(groups[week])
+		spent = abs(spent)
+		if spent > allowance {
+			fmt.Printf("  %s  %8.2f  %s over by %.2f\n", week, spent, glyph("❌", "FAIL"), spent-allowance)
+		} else {
+			fmt.Printf("  %s  %8.2f  %s\n", week, spent, glyph("✅", "ok"))
+		}
+	}
+	fmt.Println()
+}
+
+// printMonthlyCap flags months whose total expenses exceed limit and returns
+// how many did.
+func printMonthlyCap(transactions []Transaction, limit float64) int {
+	fmt.Printf("%sMonthly Cap (%.2f):\n", glyph("🧢 ", ""), limit)
+
+	var expenses []Transaction
+	for _, txn := range transactions {
+		if txn.Amount < 0 {
+			expenses = append(expenses, txn)
+		}
+	}
+
+	groups := groupByPeriod(expandInstallments(expenses), "month")
+	if len(groups) == 0 {
+		fmt.Println("  (no expenses)")
+	}
+	over := 0
+	for _, month := range sortedPeriods(groups) {
+		_, spent := totalAmounts(groups[month])
+		spent = abs(spent)
+		if spent > limit {
+			over++
+			fmt.Printf("  %s  %8.2f  %s over by %.2f\n", month, spent, glyph("❌", "FAIL"), spent-limit)
+		} else {
+			fmt.Printf("  %s  %8.2f  %s\n", month, spent, glyph("✅", "ok"))
+		}
+	}
+	fmt.Println()
+	return over
+}
+
+// sparkline draws values as a row of bar glyphs scaled between their
+// minimum and maximum.
+func sparkline(values []float64) string {
+	bars := []rune(glyph("▁▂▃▄▅▆▇█", "_.-:=+*#"))
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(bars)-1))
+		}
+		b.WriteRune(bars[i])
+	}
+	return b.String()
+}
+
+// slope is the least-squares slope of values against their index.
+func slope(values []float64) float64 {
+	n := float64(len(values))
+	var sx, sy, sxy, sxx float64
+	for i, v := range values {
+		x := float64(i)
+		sx += x
+		sy += v
+		sxy += x * v
+		sxx += x * x
+	}
+	if d := n*sxx - sx*sx; d != 0 {
+		return (n*sxy - sx*sy) / d
+	}
+	return 0
+}
+
+// printBudgetTrend shows, per budgeted tag, each month's spending minus its
+// budget (positive is over), whether that is improving or worsening, and a
+// flag when the latest three or more months were all over budget.
+func printBudgetTrend(transactions []Transaction, budgets map[string]float64) {
+	fmt.Println(glyph("📉 ", "") + "Budget Trend (actual − budget per month):")
+	start, end, ok := budgetRange(transactions)
+	if !ok {
+		fmt.Println("  (no transactions)")
+		fmt.Println()
+		return
+	}
+	groups := groupByPeriod(expandInstallments(transactions), "month")
+	var months []string
+	for m := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(end); m = m.AddDate(0, 1, 0) {
+		months = append(months, m.Format("2006-01"))
+	}
+
+	tags := make([]string, 0, len(budgets))
+	for tag := range budgets {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	fmt.Printf("  %s … %s\n", months[0], months[len(months)-1])
+	for _, tag := range tags {
+		deltas := make([]float64, len(months))
+		for i, month := range months {
+			var actual float64
+			for t, total := range tagTotals(groups[month]) {
+				if strings.EqualFold(t, tag) {
+					actual += total
+				}
+			}
+			deltas[i] = abs(min(actual, 0)) - budgets[tag]
+		}
+
+		streak := 0
+		for i := len(deltas) - 1; i >= 0 && deltas[i] > 0; i-- {
+			streak++
+		}
+
+		trend := "steady"
+		if len(deltas) > 1 {
+			switch s := slope(deltas); {
+			case s < -0.005:
+				trend = "improving"
+			case s > 0.005:
+				trend = "worsening"
+			}
+		}
+
+		series := make([]string, len(deltas))
+		for i, d := range deltas {
+			series[i] = fmt.Sprintf("%+.0f", d)
+		}
+		alert := ""
+		if streak >= 3 {
+			alert = fmt.Sprintf("  %sover budget %d months running", glyph("❌ ", "! "), streak)
+		}
+		fmt.Printf("  [%s] %s  %s  (%s)%s\n", tag, sparkline(deltas), strings.Join(series, " "), trend, alert)
+	}
+	fmt.Println()
+}
+
+// envelopeBalances is what is left in each envelope after the
+// transactions: the starting amount plus the tag's net (refunds top an
+// envelope back up).
+func envelopeBalances(transactions []Transaction, envelopes map[string]float64) map[string]float64 {
+	totals := map[string]float64{}
+	for tag, total := range tagTotals(transactions) {
+		totals[strings.ToLower(tag)] += total
+	}
+	out := map[string]float64{}
+	for tag, start := range envelopes {
+		out[tag] = start + totals[strings.ToLower(tag)]
+	}
+	return out
+}
+
+// printEnvelopes reports remaining envelope balances; with --group-by month
+// every envelope is refilled at the start of each month.
+func printEnvelopes(transactions []Transaction, envelopes map[string]float64) {
+	fmt.Println(glyph("✉️  ", "") + "Envelopes:")
+	tags := make([]string, 0, len(envelopes))
+	for tag := range envelopes {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	printRow := func(indent string, balances map[string]float64) {
+		for _, tag := range tags {
+			status := ""
+			if balances[tag] < 0 {
+				status = "  " + glyph("❌ ", "") + "overspent"
+			}
+			fmt.Printf("%s[%s] %.2f of %.2f left%s\n", indent, tag, balances[tag], envelopes[tag], status)
+		}
+	}
+
+	if period, _, _ := strings.Cut(groupBy, ","); period == "month" {
+		groups := groupByPeriod(expandInstallments(transactions), "month")
+		for _, month := range sortedPeriods(groups) {
+			fmt.Printf("  %s\n", month)
+			printRow("    ", envelopeBalances(groups[month], envelopes))
+		}
+	} else {
+		printRow("  ", envelopeBalances(transactions, envelopes))
+	}
+	fmt.Println()
+}
+
+// TagLimit tracks a tag's running balance against a --tag-limit.
+type TagLimit struct {
+	Tag          string
+	Limit        float64
+	Balance      float64   // magnitude of the tag's signed total at the end
+	Peak         float64   // largest balance along the way
+	FirstNear    time.Time // first date the balance came within the buffer; zero if never
+	FirstOver    time.Time // first date the balance exceeded the limit; zero if never
+	OverBy       float64   // how far over the limit the balance was at FirstOver
+	Transactions int
+}
+
+// tagLimits walks the transactions in date order, keeping each limited
+// tag's running total. A credit card tag's charges are negative, so the
+// balance is the magnitude of that signed total.
+func tagLimits(transactions []Transaction, limits map[string]float64, buffer float64) []TagLimit {
+	sorted := append([]Transaction(nil), transactions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	var out []TagLimit
+	for tag, limit := range limits {
+		tl := TagLimit{Tag: tag, Limit: limit}
+		var running float64
+		for _, txn := range sorted {
+			var amount float64
+			matched := false
+			for t, a := range tagAmounts(txn) {
+				if strings.EqualFold(t, tag) {
+					amount += a
+					matched = true
+				}
+			}
+			if !matched {
+				continue
+			}
+			tl.Transactions++
+			running += amount
+			balance := abs(running)
+			tl.Peak = max(tl.Peak, balance)
+			if tl.FirstNear.IsZero() && balance >= limit-buffer {
+				tl.FirstNear = txn.Date
+			}
+			if tl.FirstOver.IsZero() && balance > limit {
+				tl.FirstOver, tl.OverBy = txn.Date, balance-limit
+			}
+		}
+		tl.Balance = abs(running)
+		out = append(out, tl)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tag < out[j].Tag })
+	return out
+}
+
+// printTagLimits reports each --tag-limit: the closing balance, and when
+// it first came within --limit-buffer of the limit or went over it.
+func printTagLimits(transactions []Transaction, limits map[string]float64, buffer float64) {
+	fmt.Println(glyph("💳 ", "") + "Tag Limits:")
+	for _, tl := range tagLimits(transactions, limits, buffer) {
+		status := glyph("✅", "ok")
+		switch {
+		case tl.Balance > tl.Limit:
+			status = glyph("❌ ", "") + "over limit"
+		case tl.Balance >= tl.Limit-buffer:
+			status = glyph("⚠️  ", "") + "near limit"
+		}
+		fmt.Printf("  [%s] %.2f of %.2f  %s\n", tl.Tag, tl.Balance, tl.Limit, status)
+		if tl.Transactions == 0 {
+			fmt.Println("      (no transactions)")
+		}
+		if !tl.FirstOver.IsZero() {
+			fmt.Printf("      first over on %s (by %.2f); peak %.2f\n", dateLabel(tl.FirstOver), tl.OverBy, tl.Peak)
+		} else if !tl.FirstNear.IsZero() && buffer > 0 {
+			fmt.Printf("      within %.2f of the limit since %s; peak %.2f\n", buffer, dateLabel(tl.FirstNear), tl.Peak)
+		}
+	}
+	fmt.Println()
+}
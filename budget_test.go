@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTagLimitsCrossedMidPeriod(t *testing.T) {
+	// Out of date order on purpose: limits are walked chronologically.
+	txns := parseText(t, `# 2024-01-20
+- 2500 Laptop [CreditCard]
+# 2024-01-01
+- 2000 Flights [CreditCard]
+# 2024-01-10
+- 400 Hotel [CreditCard]
+# 2024-01-25
++ 3000 Payment [CreditCard]
+# 2024-01-28
+- 100 Dinner [Food]
+`)
+	tests := []struct {
+		name      string
+		limit     float64
+		buffer    float64
+		firstNear string
+		firstOver string
+		overBy    float64
+		peak      float64
+		balance   float64
+	}{
+		{"crossed mid-period", 4000, 0, "2024-01-20", "2024-01-20", 900, 4900, 1900},
+		{"near before over", 4000, 1700, "2024-01-10", "2024-01-20", 900, 4900, 1900},
+		{"never reached", 5000, 0, "", "", 0, 4900, 1900},
+	}
+	label := func(d time.Time) string {
+		if d.IsZero() {
+			return ""
+		}
+		return d.Format("2006-01-02")
+	}
+	for _, tt := range tests {
+		rows := tagLimits(txns, map[string]float64{"CreditCard": tt.limit}, tt.buffer)
+		if len(rows) != 1 {
+			t.Fatalf("%s: got %d rows, want 1", tt.name, len(rows))
+		}
+		tl := rows[0]
+		if got := label(tl.FirstNear); got != tt.firstNear {
+			t.Errorf("%s: first near %q, want %q", tt.name, got, tt.firstNear)
+		}
+		if got := label(tl.FirstOver); got != tt.firstOver {
+			t.Errorf("%s: first over %q, want %q", tt.name, got, tt.firstOver)
+		}
+		if math.Abs(tl.OverBy-tt.overBy) > 1e-9 || tl.Peak != tt.peak || tl.Balance != tt.balance || tl.Transactions != 4 {
+			t.Errorf("%s: over by %.2f, peak %.2f, balance %.2f, %d txns; want %.2f, %.2f, %.2f, 4",
+				tt.name, tl.OverBy, tl.Peak, tl.Balance, tl.Transactions, tt.overBy, tt.peak, tt.balance)
+		}
+	}
+}
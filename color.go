@@ -0,0 +1,35 @@
+package main
+
+import "os"
+
+// useColor reports whether ANSI coloring should be applied: only when
+// --color-by-impact is set, --plain and NO_COLOR are not, and stdout is a
+// terminal.
+func useColor() bool {
+	if !colorByImpact || plain || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// colorByMagnitude wraps text in red (negative) or green (non-negative),
+// faint for small amounts and bold for amounts near max.
+func colorByMagnitude(text string, amount, max float64) string {
+	if !useColor() {
+		return text
+	}
+	code := "32"
+	if amount < 0 {
+		code = "31"
+	}
+	if max > 0 {
+		switch ratio := abs(amount) / max; {
+		case ratio >= 0.5:
+			code = "1;" + code
+		case ratio < 0.1:
+			code = "2;" + code
+		}
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Component is one signed part of a multi-component line such as
+// "+ 4000 Salary [Income] + 500 Bonus [Bonus]". The first component is the
+// line's own amount, description and tags.
+type Component struct {
+	Amount      float64
+	Description string
+	Tags        []string // empty means the line's tags
+}
+
+var (
+	componentStartRegex = regexp.MustCompile(`\s[+-]\s*\d`)
+	componentRegex      = regexp.MustCompile(`^([+-])\s*([\d.]+)(?:\s+(.+?))??(?:\s+\[([^\]]+)\])?$`)
+	trailingProjRegex   = regexp.MustCompile(`\s+\([\d.]+\)$`)
+)
+
+// splitComponents separates the extra components from a transaction line.
+// It returns the first component's text, with any trailing projection moved
+// onto it, and the parsed extras. Signs inside [tags] do not split a line.
+// A line splits only when its first part ends in its own [tags], so
+// "- 30 Dinner - 2 people [Food]" stays one transaction; after that every
+// signed amount starts a component, and one without [tags] takes the first
+// component's. A line whose extra parts do not all parse is returned
+// unchanged.
+func splitComponents(line string) (string, []Component) {
+	var cuts []int
+	for _, loc := range componentStartRegex.FindAllStringIndex(line, -1) {
+		if loc[0] > 0 && strings.Count(line[:loc[0]], "[") == strings.Count(line[:loc[0]], "]") {
+			cuts = append(cuts, loc[0])
+		}
+	}
+	if len(cuts) == 0 || !strings.HasSuffix(strings.TrimSpace(line[:cuts[0]]), "]") {
+		return line, nil
+	}
+
+	rest := line[cuts[0]:]
+	proj := trailingProjRegex.FindString(rest)
+	rest = strings.TrimSuffix(rest, proj)
+
+	var extras []Component
+	for i := range cuts {
+		end := len(rest)
+		if i+1 < len(cuts) {
+			end = cuts[i+1] - cuts[0]
+		}
+		m := componentRegex.FindStringSubmatch(strings.TrimSpace(rest[cuts[i]-cuts[0] : end]))
+		if m == nil {
+			return line, nil
+		}
+		amount, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return line, nil
+		}
+		if m[1] == "-" {
+			amount = -amount
+		}
+		c := Component{Amount: amount, Description: strings.TrimSpace(m[3])}
+		if m[4] != "" {
+			for _, tag := range strings.Split(m[4], ",") {
+				c.Tags = append(c.Tags, strings.TrimSpace(tag))
+			}
+		}
+		extras = append(extras, c)
+	}
+	return line[:cuts[0]] + proj, extras
+}
+
+// scaleComponents keeps a transaction's components in step when its amount
+// is multiplied, e.g. by a currency rate or an installment share.
+func scaleComponents(txn Transaction, factor float64) Transaction {
+	if len(txn.Components) == 0 {
+		return txn
+	}
+	scaled := make([]Component, len(txn.Components))
+	for i, c := range txn.Components {
+		c.Amount *= factor
+		scaled[i] = c
+	}
+	txn.Components = scaled
+	return txn
+}
+
+// tagAmounts is how much of a transaction counts toward each of its
+// summary tags: the whole amount for a plain line, or each component's
+// amount toward its own tags.
+func tagAmounts(txn Transaction) map[string]float64 {
+	out := map[string]float64{}
+	if len(txn.Components) == 0 {
+		for _, tag := range summaryTags(txn) {
+			out[tag] += txn.Amount
+		}
+		return out
+	}
+	for _, c := range txn.Components {
+		tags := c.Tags
+		if len(tags) == 0 {
+			tags = txn.Components[0].Tags
+		}
+		for _, tag := range summaryTags(Transaction{Tags: tags}) {
+			out[tag] += c.Amount
+		}
+	}
+	return out
+}
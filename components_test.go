@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitComponents(t *testing.T) {
+	tests := []struct {
+		line   string
+		main   string
+		extras []Component
+	}{
+		{
+			"+ 4000 Salary [Income] + 500 Bonus [Bonus]",
+			"+ 4000 Salary [Income]",
+			[]Component{{Amount: 500, Description: "Bonus", Tags: []string{"Bonus"}}},
+		},
+		{
+			"- 120 Groceries [Food] - 30 Soap [Home, Cleaning] + 10 Refund [Food] (100)",
+			"- 120 Groceries [Food] (100)",
+			[]Component{
+				{Amount: -30, Description: "Soap", Tags: []string{"Home", "Cleaning"}},
+				{Amount: 10, Description: "Refund", Tags: []string{"Food"}},
+			},
+		},
+		// Without tags on the first part the signs are description text.
+		{"- 30 Dinner - 2 people [Food]", "- 30 Dinner - 2 people [Food]", nil},
+		{
+			"+ 4000 Salary [Income] + 500 Bonus",
+			"+ 4000 Salary [Income]",
+			[]Component{{Amount: 500, Description: "Bonus"}},
+		},
+		{
+			"- 30 Dinner [Food] - 2 Tip + 1 Change [Cash]",
+			"- 30 Dinner [Food]",
+			[]Component{{Amount: -2, Description: "Tip"}, {Amount: 1, Description: "Change", Tags: []string{"Cash"}}},
+		},
+		{"- 9.49 Coffee [Dining]", "- 9.49 Coffee [Dining]", nil},
+		{"- 20 Gift [A-1 Store]", "- 20 Gift [A-1 Store]", nil},
+	}
+	for _, tt := range tests {
+		main, extras := splitComponents(tt.line)
+		if main != tt.main || !reflect.DeepEqual(extras, tt.extras) {
+			t.Errorf("splitComponents(%q) = %q, %+v; want %q, %+v", tt.line, main, extras, tt.main, tt.extras)
+		}
+	}
+}
+
+func TestParseComponents(t *testing.T) {
+	tests := []struct {
+		line   string
+		amount float64
+		tags   map[string]float64
+	}{
+		{"+ 4000 Salary [Income] + 500 Bonus [Bonus]", 4500, map[string]float64{"Income": 4000, "Bonus": 500}},
+		{"- 120 Groceries [Food] - 30 Soap [Home] - 5 Bag [Food]", -155, map[string]float64{"Food": -125, "Home": -30}},
+		{"+ 4000 Salary [Income] + 500 Bonus", 4500, map[string]float64{"Income": 4500}},
+		{"- 30 Dinner [Food] - 2 Tip + 1 Change [Cash]", -31, map[string]float64{"Food": -32, "Cash": 1}},
+		{"- 30 Dinner - 2 people [Food]", -30, map[string]float64{"Food": -30}},
+	}
+	for _, tt := range tests {
+		txns := parseText(t, "# 2024-01-01\n"+tt.line+"\n")
+		if len(txns) != 1 {
+			t.Fatalf("%q: got %d transactions, want 1", tt.line, len(txns))
+		}
+		if txns[0].Amount != tt.amount {
+			t.Errorf("%q: amount %.2f, want %.2f", tt.line, txns[0].Amount, tt.amount)
+		}
+		if got := tagAmounts(txns[0]); !reflect.DeepEqual(got, tt.tags) {
+			t.Errorf("%q: tag amounts %v, want %v", tt.line, got, tt.tags)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// exportCSV writes transactions with a date,amount,description,tags,projected
+// header; tags are joined with ";". With --csv-running-balance the rows are
+// sorted by date and time (keeping file order otherwise) and a balance column
+// accumulates from --starting-balance, so row order follows date order.
+func exportCSV(transactions []Transaction, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rows := transactions
+	header := []string{"date", "amount", "description", "tags", "projected"}
+	if csvRunningBalance {
+		rows = append([]Transaction(nil), transactions...)
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].Date.Before(rows[j].Date)
+		})
+		header = append(header, "balance")
+	}
+
+	w := csv.NewWriter(f)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	balance := startingBalance
+	for _, txn := range rows {
+		projected := ""
+		if txn.ProjectedAmount != nil {
+			projected = strconv.FormatFloat(*txn.ProjectedAmount, 'f', 2, 64)
+		}
+		record := []string{
+			dateLabel(txn.Date),
+			strconv.FormatFloat(txn.Amount, 'f', 2, 64),
+			txn.Description,
+			strings.Join(txn.Tags, ";"),
+			projected,
+		}
+		if csvRunningBalance {
+			balance += txn.Amount
+			record = append(record, strconv.FormatFloat(balance, 'f', 2, 64))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportCSVRunningBalance(t *testing.T) {
+	defer func(v bool) { csvRunningBalance = v }(csvRunningBalance)
+	defer func(v float64) { startingBalance = v }(startingBalance)
+	csvRunningBalance = true
+
+	tests := []struct {
+		name    string
+		ledger  string
+		start   float64
+		dates   []string
+		balance string
+	}{
+		{"sorted", "# 2024-01-01\n+ 100 Pay [Income]\n# 2024-01-02\n- 30.25 Food [Food]\n", 0, []string{"2024-01-01", "2024-01-02"}, "69.75"},
+		{"from starting balance", "# 2024-01-01\n- 30 Food [Food]\n- 20 Bus [Transport]\n", 500, []string{"2024-01-01", "2024-01-01"}, "450.00"},
+		{"reordered by date", "# 2024-02-01\n- 10 Late [Food]\n# 2024-01-01\n+ 50 Early [Income]\n", 0, []string{"2024-01-01", "2024-02-01"}, "40.00"},
+	}
+	for _, tt := range tests {
+		startingBalance = tt.start
+		filename := filepath.Join(t.TempDir(), "out.csv")
+		if err := exportCSV(parseText(t, tt.ledger), filename); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		f, err := os.Open(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records, err := csv.NewReader(f).ReadAll()
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if records[0][len(records[0])-1] != "balance" {
+			t.Errorf("%s: header %q lacks a balance column", tt.name, records[0])
+		}
+		rows := records[1:]
+		for i, date := range tt.dates {
+			if rows[i][0] != date {
+				t.Errorf("%s: row %d dated %s, want %s", tt.name, i, rows[i][0], date)
+			}
+		}
+		if got := rows[len(rows)-1][5]; got != tt.balance {
+			t.Errorf("%s: final balance %s, want %s", tt.name, got, tt.balance)
+		}
+	}
+}
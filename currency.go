@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// datedRate is a conversion rate that takes effect on Date.
+type datedRate struct {
+	Date time.Time
+	Rate float64
+}
+
+// RateTable holds flat --rates plus optional dated rates from --rates-file.
+type RateTable struct {
+	Flat  map[string]float64
+	Dated map[string][]datedRate // per currency, sorted by date
+}
+
+// Rate returns the rate for currency on date: the latest dated rate on or
+// before date, falling back to the flat rate when none is that old.
+func (t RateTable) Rate(currency string, date time.Time) (float64, bool) {
+	history := t.Dated[currency]
+	i := sort.Search(len(history), func(i int) bool {
+		return history[i].Date.After(date)
+	})
+	if i > 0 {
+		return history[i-1].Rate, true
+	}
+	rate, ok := t.Flat[currency]
+	return rate, ok
+}
+
+// loadRatesFile reads date,currency,rate rows (an optional header row is
+// skipped) into per-currency histories.
+func loadRatesFile(filename string) (map[string][]datedRate, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string][]datedRate{}
+	for i, rec := range records {
+		if i == 0 && len(rec) > 0 && strings.EqualFold(strings.TrimSpace(rec[0]), "date") {
+			continue
+		}
+		if len(rec) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected date,currency,rate", filename, i+1)
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(rec[0]))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid date %q", filename, i+1, rec[0])
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rec[2]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid rate %q", filename, i+1, rec[2])
+		}
+		cur := strings.ToUpper(strings.TrimSpace(rec[1]))
+		out[cur] = append(out[cur], datedRate{Date: date, Rate: rate})
+	}
+	for _, history := range out {
+		sort.SliceStable(history, func(i, j int) bool {
+			return history[i].Date.Before(history[j].Date)
+		})
+	}
+	return out, nil
+}
+
+// isoCurrencies holds the active ISO 4217 currency codes.
+var isoCurrencies = map[string]bool{
+	"AED": true, "AFN": true, "ALL": true, "AMD": true, "ANG": true,
+	"AOA": true, "ARS": true, "AUD": true, "AWG": true, "AZN": true,
+	"BAM": true, "BBD": true, "BDT": true, "BGN": true, "BHD": true,
+	"BIF": true, "BMD": true, "BND": true, "BOB": true, "BRL": true,
+	"BSD": true, "BTN": true, "BWP": true, "BYN": true, "BZD": true,
+	"CAD": true, "CDF": true, "CHF": true, "CLP": true, "CNY": true,
+	"COP": true, "CRC": true, "CUP": true, "CVE": true, "CZK": true,
+	"DJF": true, "DKK": true, "DOP": true, "DZD": true, "EGP": true,
+	"ERN": true, "ETB": true, "EUR": true, "FJD": true, "FKP": true,
+	"GBP": true, "GEL": true, "GHS": true, "GIP": true, "GMD": true,
+	"GNF": true, "GTQ": true, "GYD": true, "HKD": true, "HNL": true,
+	"HTG": true, "HUF": true, "IDR": true, "ILS": true, "INR": true,
+	"IQD": true, "IRR": true, "ISK": true, "JMD": true, "JOD": true,
+	"JPY": true, "KES": true, "KGS": true, "KHR": true, "KMF": true,
+	"KPW": true, "KRW": true, "KWD": true, "KYD": true, "KZT": true,
+	"LAK": true, "LBP": true, "LKR": true, "LRD": true, "LSL": true,
+	"LYD": true, "MAD": true, "MDL": true, "MGA": true, "MKD": true,
+	"MMK": true, "MNT": true, "MOP": true, "MRU": true, "MUR": true,
+	"MVR": true, "MWK": true, "MXN": true, "MYR": true, "MZN": true,
+	"NAD": true, "NGN": true, "NIO": true, "NOK": true, "NPR": true,
+	"NZD": true, "OMR": true, "PAB": true, "PEN": true, "PGK": true,
+	"PHP": true, "PKR": true, "PLN": true, "PYG": true, "QAR": true,
+	"RON": true, "RSD": true, "RUB": true, "RWF": true, "SAR": true,
+	"SBD": true, "SCR": true, "SDG": true, "SEK": true, "SGD": true,
+	"SHP": true, "SLE": true, "SOS": true, "SRD": true, "SSP": true,
+	"STN": true, "SVC": true, "SYP": true, "SZL": true, "THB": true,
+	"TJS": true, "TMT": true, "TND": true, "TOP": true, "TRY": true,
+	"TTD": true, "TWD": true, "TZS": true, "UAH": true, "UGX": true,
+	"USD": true, "UYU": true, "UZS": true, "VES": true, "VND": true,
+	"VUV": true, "WST": true, "XAF": true, "XCD": true, "XOF": true,
+	"XPF": true, "YER": true, "ZAR": true, "ZMW": true, "ZWL": true,
+}
+
+// ratesFileCurrencies caches the currencies named in --rates-file.
+var ratesFileCurrencies map[string][]datedRate
+
+// knownCurrency reports whether code may follow an amount as its currency:
+// an ISO 4217 code, or one given a rate by --rates or --rates-file. Any
+// other three capitals (ATM, CVS, TAX) begin the description instead.
+func knownCurrency(code string) bool {
+	if isoCurrencies[code] {
+		return true
+	}
+	for cur := range parseAdjustments(rates) {
+		if strings.EqualFold(strings.TrimSpace(cur), code) {
+			return true
+		}
+	}
+	if ratesFile != "" && ratesFileCurrencies == nil {
+		// An unreadable file is reported when rates are loaded.
+		ratesFileCurrencies, _ = loadRatesFile(ratesFile)
+	}
+	_, ok := ratesFileCurrencies[code]
+	return ok
+}
+
+// parseTagCurrencies parses "Travel=EUR,Work=GBP" into a tag → currency map.
+// Tags are matched case-insensitively, so keys are lowercased.
+func parseTagCurrencies(s string) map[string]string {
+	out := map[string]string{}
+	for _, entry := range splitList(s) {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		out[strings.ToLower(strings.TrimSpace(parts[0]))] = strings.ToUpper(strings.TrimSpace(parts[1]))
+	}
+	return out
+}
+
+// convertCurrencies converts every foreign-denominated transaction into the
+// base currency using rates (base units per one foreign unit) effective on
+// the transaction's date. A transaction
+// without its own Currency picks one up from the first of its tags listed in
+// tagCurrency. Amounts already in --base-currency keep their value, and one
+// whose currency has no rate is left unconverted with a warning. Every
+// transaction comes back with Currency cleared.
+func convertCurrencies(transactions []Transaction, rates RateTable, tagCurrency map[string]string) []Transaction {
+	out := make([]Transaction, 0, len(transactions))
+	for _, txn := range transactions {
+		if txn.Currency == "" {
+			for _, tag := range txn.Tags {
+				if cur, ok := tagCurrency[strings.ToLower(tag)]; ok {
+					txn.Currency = cur
+					break
+				}
+			}
+		}
+		if txn.Currency != "" && !strings.EqualFold(txn.Currency, baseCurrency) {
+			rate, ok := rates.Rate(txn.Currency, txn.Date)
+			if !ok {
+				warnf("no --rates entry for %s (needed by %q on %s); amount left unconverted", txn.Currency, txn.Description, txn.Date.Format("2006-01-02"))
+				rate = 1
+			}
+			txn.Amount *= rate
+			txn = scaleComponents(txn, rate)
+			if txn.ProjectedAmount != nil {
+				p := *txn.ProjectedAmount * rate
+				txn.ProjectedAmount = &p
+			}
+		}
+		txn.Currency = ""
+		out = append(out, txn)
+	}
+	return out
+}
+
+func hasForeignCurrency(transactions []Transaction) bool {
+	for _, txn := range transactions {
+		if txn.Currency != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// currencyDecimals lists currencies whose minor unit is not cents.
+var currencyDecimals = map[string]int{
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "JOD": 3, "TND": 3,
+}
+
+// currencyPrecision is the number of decimal places an amount in cur may
+// carry; the base currency (cur == "") uses two.
+func currencyPrecision(cur string) int {
+	if d, ok := currencyDecimals[cur]; ok {
+		return d
+	}
+	return 2
+}
+
+// excessPrecision reports whether a literal amount such as "9.499" has more
+// decimal places than its currency allows.
+func excessPrecision(literal, cur string) bool {
+	_, frac, ok := strings.Cut(literal, ".")
+	return ok && len(frac) > currencyPrecision(cur)
+}
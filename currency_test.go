@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRateTableRate(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	table := RateTable{
+		Flat: map[string]float64{"EUR": 1.0, "GBP": 1.3},
+		Dated: map[string][]datedRate{
+			"EUR": {{Date: day("2024-01-01"), Rate: 1.10}, {Date: day("2024-06-01"), Rate: 1.20}},
+			"JPY": {{Date: day("2024-01-01"), Rate: 0.007}},
+		},
+	}
+	tests := []struct {
+		currency string
+		date     string
+		rate     float64
+		ok       bool
+	}{
+		{"EUR", "2023-12-31", 1.0, true}, // before any dated rate: flat
+		{"EUR", "2024-01-01", 1.10, true},
+		{"EUR", "2024-03-15", 1.10, true}, // nearest prior rate
+		{"EUR", "2024-06-01", 1.20, true},
+		{"EUR", "2025-01-01", 1.20, true}, // after the last dated rate
+		{"GBP", "2024-03-15", 1.3, true},  // flat only
+		{"JPY", "2023-12-31", 0, false},   // no flat fallback
+		{"JPY", "2024-02-01", 0.007, true},
+		{"CHF", "2024-02-01", 0, false},
+	}
+	for _, tt := range tests {
+		rate, ok := table.Rate(tt.currency, day(tt.date))
+		if rate != tt.rate || ok != tt.ok {
+			t.Errorf("Rate(%s, %s) = %v, %v; want %v, %v", tt.currency, tt.date, rate, ok, tt.rate, tt.ok)
+		}
+	}
+}
+
+func TestConvertCurrencies(t *testing.T) {
+	defer func(v string) { baseCurrency = v }(baseCurrency)
+	defer func(w []string) { warnings = w }(warnings)
+
+	table := RateTable{Flat: map[string]float64{"EUR": 1.1}}
+	tests := []struct {
+		name     string
+		base     string
+		line     string
+		amount   float64
+		warnings int
+	}{
+		{"foreign with a rate", "USD", "- 50 EUR Dinner [Food]", -55, 0},
+		{"base currency needs no rate", "USD", "- 50 USD Lunch [Food]", -50, 0},
+		{"base currency matched case-insensitively", "usd", "- 50 USD Lunch [Food]", -50, 0},
+		{"other base currency", "EUR", "- 50 EUR Dinner [Food]", -50, 0},
+		{"no rate is a warning", "USD", "- 50 GBP Tea [Food]", -50, 1},
+	}
+	for _, tt := range tests {
+		baseCurrency, warnings = tt.base, nil
+		txns := convertCurrencies(parseText(t, "# 2024-01-01\n"+tt.line+"\n"), table, nil)
+		if len(txns) != 1 {
+			t.Fatalf("%s: got %d transactions, want 1", tt.name, len(txns))
+		}
+		if math.Abs(txns[0].Amount-tt.amount) > 1e-9 || txns[0].Currency != "" {
+			t.Errorf("%s: got %.2f %q, want %.2f in the base currency", tt.name, txns[0].Amount, txns[0].Currency, tt.amount)
+		}
+		if len(warnings) != tt.warnings {
+			t.Errorf("%s: warnings %q, want %d", tt.name, warnings, tt.warnings)
+		}
+	}
+}
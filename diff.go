@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// printDiff compares the ledger against --diff. The default "transactions"
+// mode lists transactions only one side has (matched by transactionHash,
+// so a repeated entry must be repeated on both sides); "tags" compares
+// per-tag totals instead.
+func printDiff(current, other []Transaction, otherFile, mode string) {
+	switch mode {
+	case "tags":
+		printSideBySide(Projection{
+			Original:  current,
+			Projected: other,
+			Title:     fmt.Sprintf("Tag Diff (%s → %s)", file, otherFile),
+			ShowDelta: true,
+		})
+	case "transactions":
+		fmt.Printf("%sTransaction Diff (%s → %s):\n", glyph("🔍 ", ""), file, otherFile)
+		remaining := map[string]int{}
+		for _, txn := range other {
+			remaining[transactionHash(txn)]++
+		}
+		changes := 0
+		for _, txn := range current {
+			h := transactionHash(txn)
+			if remaining[h] > 0 {
+				remaining[h]--
+				continue
+			}
+			fmt.Printf("  - %s %s\n", txn.Date.Format("2006-01-02"), formatTransactionLine(txn))
+			changes++
+		}
+		for _, txn := range other {
+			h := transactionHash(txn)
+			if remaining[h] > 0 {
+				remaining[h]--
+				fmt.Printf("  + %s %s\n", txn.Date.Format("2006-01-02"), formatTransactionLine(txn))
+				changes++
+			}
+		}
+		if changes == 0 {
+			fmt.Println("  (no differences)")
+		}
+		fmt.Println()
+	default:
+		fmt.Printf("Unknown --diff-mode %q (supported: transactions, tags)\n", mode)
+		os.Exit(1)
+	}
+}
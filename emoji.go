@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// emojiNames gives the section icons friendly names for --emoji-set.
+var emojiNames = map[string]string{
+	"summary":  "📊",
+	"tags":     "📌",
+	"impact":   "🔥",
+	"export":   "📁",
+	"changes":  "🔍",
+	"warning":  "⚠️",
+	"ok":       "✅",
+	"fail":     "❌",
+	"calendar": "🗓",
+	"goal":     "🎯",
+}
+
+// asciiEmoji is the built-in "ascii" preset: decoration that renders in
+// any terminal font.
+var asciiEmoji = map[string]string{
+	"📊": "[=]", "📌": "[#]", "🔥": "[!]", "📁": "[>]", "🔍": "[?]",
+	"⚠️": "[!]", "⚠": "!", "📉": "[\\]", "📈": "[/]", "📅": "[w]", "📐": "[p]", "🔮": "[f]", "⏳": "[t]", "✉️": "[e]", "🔎": "[f]", "📏": "[r]", "✅": "[ok]", "❌": "[x]", "🗓": "[d]",
+	"🎯": "[o]", "↪️": "->", "🆕": "[+]", "🎚": "[~]", "👛": "[$]",
+	"💰": "[$]", "📶": "[|]", "🔁": "[r]", "🔗": "[&]", "🛫": "[^]",
+	"🧢": "[^]", "🧮": "[%]", "🧹": "[*]", "🧾": "[r]", "🏪": "[m]", "⏱": "[t]", "💳": "[c]", "🧩": "[+]",
+	"█": "#", "▲": "^", "▼": "v", "▬": "=",
+	"▁▂▃▄▅▆▇█": "_.-:=+*#", // sparkline bars, lowest to highest
+}
+
+// emojiOverrides maps a default glyph to its --emoji-set replacement.
+var emojiOverrides = map[string]string{}
+
+// loadEmojiSet applies an --emoji-set spec: a comma-separated list of the
+// "default" or "ascii" presets and name=glyph overrides, where name is one
+// of emojiNames or the emoji itself, e.g. "ascii,summary=>>".
+func loadEmojiSet(spec string) error {
+	for _, item := range splitList(spec) {
+		name, repl, ok := strings.Cut(item, "=")
+		if !ok {
+			switch item {
+			case "default":
+				emojiOverrides = map[string]string{}
+			case "ascii":
+				for k, v := range asciiEmoji {
+					emojiOverrides[k] = v
+				}
+			default:
+				return fmt.Errorf("unknown --emoji-set preset %q (supported: default, ascii)", item)
+			}
+			continue
+		}
+		if e, known := emojiNames[strings.ToLower(strings.TrimSpace(name))]; known {
+			name = e
+		}
+		emojiOverrides[strings.TrimSpace(name)] = repl
+	}
+	return nil
+}
+
+// customGlyph swaps the icon in fancy for its --emoji-set override, keeping
+// a single separating space where the original had padding.
+func customGlyph(fancy string) string {
+	icon := strings.TrimSpace(fancy)
+	repl, ok := emojiOverrides[icon]
+	if !ok {
+		return fancy
+	}
+	if strings.HasPrefix(fancy, " ") {
+		repl = " " + repl
+	}
+	if strings.HasSuffix(fancy, " ") {
+		repl += " "
+	}
+	return repl
+}
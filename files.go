@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ledgerFiles lists the *.md files in dir (and its subdirectories with
+// --recursive) in sorted path order, so aggregation is deterministic.
+func ledgerFiles(dir string, recursive bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), ".md") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// parseFiles parses each file (from --dir or a comma-separated --file) and
+// concatenates the results, transactions and notes alike. A file that fails
+// is reported by name and skipped. Transactions that appear in more than one
+// file are warned about as likely double imports; with
+// --drop-cross-file-dupes only the first file's copies are kept.
+func parseFiles(files []string) ([]Transaction, map[string][]string) {
+	var all []Transaction
+	notes := map[string][]string{}
+	firstFile := map[string]string{}   // transaction hash → first file it appeared in
+	dupeFiles := map[string][]string{} // transaction hash → every file, for dupes
+	var dupeOrder []string
+	for _, filename := range files {
+		txns, fileNotes, err := parseFile(filename)
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", filename, err)
+			continue
+		}
+		for key, lines := range fileNotes {
+			notes[key] = append(notes[key], lines...)
+		}
+		seenHere := map[string]bool{}
+		for _, txn := range txns {
+			h := transactionHash(txn)
+			first, seen := firstFile[h]
+			if !seen {
+				firstFile[h] = filename
+			} else if first != filename {
+				if !seenHere[h] {
+					if dupeFiles[h] == nil {
+						dupeOrder = append(dupeOrder, h)
+						dupeFiles[h] = []string{first}
+					}
+					dupeFiles[h] = append(dupeFiles[h], filename)
+				}
+				seenHere[h] = true
+				if dropCrossFileDupes {
+					continue
+				}
+			}
+			all = append(all, txn)
+		}
+	}
+
+	hint := "use --drop-cross-file-dupes to keep only the first file's copy"
+	if dropCrossFileDupes {
+		hint = "kept only the first file's copy"
+	}
+	for _, h := range dupeOrder {
+		warnf("possible double import: %s in %s (%s)", dupeLabel(all, h), strings.Join(dupeFiles[h], ", "), hint)
+	}
+	return all, notes
+}
+
+// transactionHash identifies a transaction by its date, amount,
+// description and tags, ignoring case and tag order.
+func transactionHash(txn Transaction) string {
+	tags := make([]string, len(txn.Tags))
+	for i, tag := range txn.Tags {
+		tags[i] = strings.ToLower(tag)
+	}
+	sort.Strings(tags)
+	key := fmt.Sprintf("%s|%.2f|%s|%s", txn.Date.Format("2006-01-02"), txn.Amount, strings.ToLower(txn.Description), strings.Join(tags, ","))
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+func dupeLabel(transactions []Transaction, hash string) string {
+	for _, txn := range transactions {
+		if transactionHash(txn) == hash {
+			return txn.Date.Format("2006-01-02") + " " + formatTransactionLine(txn)
+		}
+	}
+	return hash
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFilesDoubleImports(t *testing.T) {
+	defer func(v bool) { dropCrossFileDupes = v }(dropCrossFileDupes)
+	defer func(w []string) { warnings = w }(warnings)
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.md")
+	b := filepath.Join(dir, "b.md")
+	if err := os.WriteFile(a, []byte("# 2024-01-01\n- 40 Dinner [Food]\n- 5 Coffee [Food]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("# 2024-01-01\n- 40 dinner [food]\n# 2024-01-02\n- 9 Lunch [Food]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		drop  bool
+		count int
+		hint  string
+	}{
+		{false, 4, "use --drop-cross-file-dupes"},
+		{true, 3, "kept only the first file's copy"},
+	}
+	for _, tt := range tests {
+		dropCrossFileDupes, warnings = tt.drop, nil
+		txns, _ := parseFiles([]string{a, b})
+		if len(txns) != tt.count {
+			t.Errorf("drop=%v: got %d transactions, want %d", tt.drop, len(txns), tt.count)
+		}
+		if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "possible double import: 2024-01-01 - 40 Dinner [Food] in "+a+", "+b) ||
+			!strings.Contains(warnings[0], tt.hint) {
+			t.Errorf("drop=%v: warnings %q", tt.drop, warnings)
+		}
+	}
+}
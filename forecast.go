@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TagForecast is a tag's typical month and what it adds up to over the
+// --forecast horizon.
+type TagForecast struct {
+	Tag      string
+	Months   int // months divided by: the span, or the tag's active months
+	Average  float64
+	Forecast float64
+}
+
+// tagForecasts averages each tag per month. With --forecast-average active
+// a tag's total is divided by its active months (months with at least one
+// transaction carrying the tag); with span (the default) by every month
+// from the first to the last transaction, which is more conservative when
+// a tag only appears in some months.
+func tagForecasts(transactions []Transaction, horizon int, mode string) ([]TagForecast, error) {
+	if mode != "span" && mode != "active" {
+		return nil, fmt.Errorf("unknown --forecast-average %q (supported: span, active)", mode)
+	}
+	span := monthSpan(transactions)
+	active := map[string]map[string]bool{}
+	totals := map[string]float64{}
+	for _, txn := range transactions {
+		month := txn.Date.Format("2006-01")
+		for tag, amount := range tagAmounts(txn) {
+			if active[tag] == nil {
+				active[tag] = map[string]bool{}
+			}
+			active[tag][month] = true
+			totals[tag] += amount
+		}
+	}
+
+	var out []TagForecast
+	for tag, total := range totals {
+		months := span
+		if mode == "active" {
+			months = len(active[tag])
+		}
+		avg := total / float64(months)
+		out = append(out, TagForecast{Tag: tag, Months: months, Average: avg, Forecast: avg * float64(horizon)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tag < out[j].Tag })
+	return out, nil
+}
+
+func printForecast(transactions []Transaction, horizon int, mode string) {
+	fmt.Printf("%sForecast over %d month(s) (%s average):\n", glyph("🔮 ", ""), horizon, mode)
+	rows, err := tagForecasts(transactions, horizon, mode)
+	if err != nil {
+		fmt.Println("  Error:", err)
+		fmt.Println()
+		return
+	}
+	if len(rows) == 0 {
+		fmt.Println("  (no transactions)")
+	}
+	for _, r := range rows {
+		fmt.Printf("  [%s] %.2f/month over %d month(s) → %.2f\n", r.Tag, r.Average, r.Months, r.Forecast)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTagForecastsSpanVsActive(t *testing.T) {
+	// Four months of data; Gym only shows up in two of them.
+	txns := parseText(t, `# 2024-01-05
+- 100 Rent [Housing]
+- 60 Gym [Gym]
+# 2024-02-05
+- 100 Rent [Housing]
+# 2024-03-05
+- 100 Rent [Housing]
+- 60 Gym [Gym]
+# 2024-04-05
+- 100 Rent [Housing]
+`)
+	tests := []struct {
+		mode     string
+		tag      string
+		months   int
+		average  float64
+		forecast float64
+	}{
+		{"span", "Housing", 4, -100, -300},
+		{"active", "Housing", 4, -100, -300},
+		{"span", "Gym", 4, -30, -90},
+		{"active", "Gym", 2, -60, -180},
+	}
+	for _, tt := range tests {
+		rows, err := tagForecasts(txns, 3, tt.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, r := range rows {
+			if r.Tag != tt.tag {
+				continue
+			}
+			found = true
+			if r.Months != tt.months || math.Abs(r.Average-tt.average) > 1e-9 || math.Abs(r.Forecast-tt.forecast) > 1e-9 {
+				t.Errorf("%s [%s]: got %d months, average %.2f, forecast %.2f; want %d, %.2f, %.2f",
+					tt.mode, tt.tag, r.Months, r.Average, r.Forecast, tt.months, tt.average, tt.forecast)
+			}
+		}
+		if !found {
+			t.Errorf("%s: no forecast for [%s]", tt.mode, tt.tag)
+		}
+	}
+
+	if _, err := tagForecasts(txns, 3, "median"); err == nil {
+		t.Error("unknown --forecast-average mode was accepted")
+	}
+}
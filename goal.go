@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// weeklySavingsRate averages net cash flow over the trailing window of
+// weeks ending with the latest transaction; quiet weeks count as zero.
+func weeklySavingsRate(transactions []Transaction, weeks int) (rate float64, asOf time.Time) {
+	for _, txn := range transactions {
+		if txn.Date.After(asOf) {
+			asOf = txn.Date
+		}
+	}
+	start := asOf.AddDate(0, 0, -7*weeks)
+	var net float64
+	for _, txn := range transactions {
+		if txn.Date.After(start) {
+			net += txn.Amount
+		}
+	}
+	return net / float64(weeks), asOf
+}
+
+// printGoal counts down the weeks to a savings goal. Progress is the
+// current balance: --starting-balance plus the net of all transactions.
+func printGoal(transactions []Transaction) {
+	fmt.Printf("%sSavings Goal (%.2f):\n", glyph("🎯 ", ""), goal)
+	if len(transactions) == 0 || savingsWindow <= 0 {
+		fmt.Println("  (no transactions)")
+		fmt.Println()
+		return
+	}
+
+	income, expenses := totalAmounts(transactions)
+	progress := startingBalance + income + expenses
+	remaining := goal - progress
+	rate, asOf := weeklySavingsRate(transactions, savingsWindow)
+
+	fmt.Printf("  Progress:            %.2f (%.1f%%)\n", progress, progress/goal*100)
+	fmt.Printf("  Weekly savings rate: %.2f (last %d weeks to %s)\n", rate, savingsWindow, asOf.Format("2006-01-02"))
+	switch {
+	case remaining <= 0:
+		fmt.Println("  Goal reached")
+	case rate <= 0:
+		fmt.Println("  Not on track (savings rate is not positive)")
+	default:
+		weeks := int(math.Ceil(remaining / rate))
+		fmt.Printf("  Weeks to goal:       %d (around %s)\n", weeks, asOf.AddDate(0, 0, 7*weeks).Format("2006-01-02"))
+	}
+
+	if goalDate != "" && remaining > 0 {
+		target, err := time.Parse("2006-01-02", goalDate)
+		if err != nil {
+			fmt.Println("  Invalid --goal-date format")
+		} else if weeks := target.Sub(asOf).Hours() / 24 / 7; weeks <= 0 {
+			fmt.Printf("  --goal-date %s has already passed\n", goalDate)
+		} else {
+			fmt.Printf("  Needed by %s: %.2f/week\n", goalDate, remaining/weeks)
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// periodKey labels the period a date falls in. Labels sort chronologically.
+// Quarters honour --fiscal-year-start; a fiscal year is labelled by the
+// calendar year it starts in. period must pass checkGroupBy.
+func periodKey(date time.Time, period string) string {
+	switch period {
+	case "day":
+		return date.Format("2006-01-02")
+	case "week":
+		year, week := date.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "quarter":
+		offset := (int(date.Month()) - fiscalYearStart + 12) % 12
+		year := date.Year()
+		if int(date.Month()) < fiscalYearStart {
+			year--
+		}
+		return fmt.Sprintf("%d-Q%d", year, offset/3+1)
+	case "year":
+		return date.Format("2006")
+	default:
+		return date.Format("2006-01")
+	}
+}
+
+// checkGroupBy validates a --group-by value such as "month" or "month,tag"
+// and, for quarters, --fiscal-year-start.
+func checkGroupBy(spec string) error {
+	period, nested, _ := strings.Cut(spec, ",")
+	switch period {
+	case "day", "week", "month", "quarter", "year":
+	default:
+		return fmt.Errorf("unknown period %q (supported: day, week, month, quarter, year)", period)
+	}
+	if nested != "" && nested != "tag" {
+		return fmt.Errorf("unknown subgroup %q (only \"tag\" is supported)", nested)
+	}
+	if period == "quarter" && (fiscalYearStart < 1 || fiscalYearStart > 12) {
+		return fmt.Errorf("invalid --fiscal-year-start %d", fiscalYearStart)
+	}
+	return nil
+}
+
+// groupByPeriod buckets transactions by the period their date falls in.
+func groupByPeriod(txns []Transaction, period string) map[string][]Transaction {
+	out := map[string][]Transaction{}
+	for _, txn := range txns {
+		key := periodKey(txn.Date, period)
+		out[key] = append(out[key], txn)
+	}
+	return out
+}
+
+func sortedPeriods(groups map[string][]Transaction) []string {
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// expandInstallments replaces each installment purchase with one entry per
+// month, starting in the purchase month, each carrying an equal share of the
+// amount. The day of month is clamped so Jan 31 is followed by Feb 28/29.
+func expandInstallments(transactions []Transaction) []Transaction {
+	var out []Transaction
+	for _, txn := range transactions {
+		n := txn.Installments
+		if n <= 1 {
+			out = append(out, txn)
+			continue
+		}
+		for i := 0; i < n; i++ {
+			part := scaleComponents(txn, 1/float64(n))
+			part.Date = addMonthsClamped(txn.Date, i)
+			part.Amount = txn.Amount / float64(n)
+			if txn.ProjectedAmount != nil {
+				p := *txn.ProjectedAmount / float64(n)
+				part.ProjectedAmount = &p
+			}
+			part.Description = fmt.Sprintf("%s (%d/%d)", txn.Description, i+1, n)
+			part.Installments = 0
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func addMonthsClamped(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(date.Day(), lastDay)-1)
+}
+
+// printPeriodSubtotals prints income, expenses and net per period. A
+// compound such as "month,tag" nests each period's tag totals under it, and
+// by day each date is followed by its "> note" lines.
+func printPeriodSubtotals(w io.Writer, transactions []Transaction, groupBy string) {
+	period, nested, _ := strings.Cut(groupBy, ",")
+	groups := groupByPeriod(expandInstallments(transactions), period)
+	if period == "day" {
+		// A day with notes but no transactions still gets a row for them.
+		for date := range dayNotes {
+			if _, ok := groups[date]; !ok && (fromDate == "" || date >= fromDate) && (toDate == "" || date <= toDate) {
+				groups[date] = nil
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n%sBy %s:\n", glyph("🗓  ", ""), period)
+	trends := showNetTrend
+	if note := dataShortfall(transactions); trends && note != "" {
+		fmt.Fprintf(w, "  (net trend hidden: %s)\n", note)
+		trends = false
+	}
+	target := netTarget != 0 && period == "month"
+	var prevNet, cumulative float64
+	for i, key := range sortedPeriods(groups) {
+		income, expenses := totalAmounts(groups[key])
+		net := income + expenses
+		trend := ""
+		if trends && i > 0 {
+			trend = "  " + netTrend(net, prevNet)
+		}
+		if target {
+			cumulative += net - netTarget
+			trend += fmt.Sprintf("  (target %.2f) %s", netTarget, targetVariance(net-netTarget))
+		}
+		fmt.Fprintf(w, "  %-10s Income: %10.2f  Expenses: %10.2f  Net: %10.2f%s\n",
+			key, income, abs(expenses), net, trend)
+		prevNet = net
+		if period == "day" {
+			for _, note := range dayNotes[key] {
+				fmt.Fprintf(w, "      > %s\n", note)
+			}
+		}
+		if nested == "tag" {
+			printNestedTags(w, groups[key])
+		}
+	}
+	if target {
+		fmt.Fprintf(w, "  Cumulative vs target: %s\n", targetVariance(cumulative))
+	}
+}
+
+// targetVariance describes how far a net is ahead of or behind --net-target.
+func targetVariance(variance float64) string {
+	switch {
+	case variance > 0.005:
+		return fmt.Sprintf("ahead by %.2f", variance)
+	case variance < -0.005:
+		return fmt.Sprintf("behind by %.2f", -variance)
+	default:
+		return "on target"
+	}
+}
+
+// printNestedTags lists tag totals within one period, largest impact first.
+func printNestedTags(w io.Writer, transactions []Transaction) {
+	totals := tagTotals(transactions)
+	tags := make([]string, 0, len(totals))
+	for tag := range totals {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		a, b := abs(totals[tags[i]]), abs(totals[tags[j]])
+		if a != b {
+			return a > b
+		}
+		return tags[i] < tags[j]
+	})
+	for _, tag := range tags {
+		fmt.Fprintf(w, "      [%s] %.2f\n", tag, totals[tag])
+	}
+}
+
+// netTrend renders the direction and size of a change in net between two
+// consecutive periods.
+func netTrend(net, prev float64) string {
+	delta := net - prev
+	switch {
+	case delta > 0.005:
+		return fmt.Sprintf("%s %+.2f", glyph("▲", "+"), delta)
+	case delta < -0.005:
+		return fmt.Sprintf("%s %+.2f", glyph("▼", "-"), delta)
+	default:
+		return glyph("▬", "=") + " 0.00"
+	}
+}
+
+// periodDays is the average length of a --normalize-to period.
+var periodDays = map[string]float64{"day": 1, "week": 7, "month": 365.25 / 12, "year": 365.25}
+
+// checkNormalizeTo validates a --normalize-to period.
+func checkNormalizeTo(period string) error {
+	if _, ok := periodDays[period]; !ok {
+		return fmt.Errorf("unknown period %q (supported: day, week, month, year)", period)
+	}
+	return nil
+}
+
+// normalizeFactor scales totals over the transactions' date span (counted
+// inclusively in days) to a rate per period.
+func normalizeFactor(transactions []Transaction, period string) (factor float64, spanDays int, err error) {
+	if err := checkNormalizeTo(period); err != nil {
+		return 0, 0, err
+	}
+	perPeriod := periodDays[period]
+	first, last, ok := dateRange(transactions)
+	if !ok {
+		return 0, 0, fmt.Errorf("no transactions to normalize")
+	}
+	spanDays = int(last.Sub(first).Hours()/24) + 1
+	return perPeriod / float64(spanDays), spanDays, nil
+}
+
+// printNormalized restates income, expenses, net and tag totals as rates
+// per --normalize-to period.
+func printNormalized(w io.Writer, transactions []Transaction, period string) {
+	factor, span, err := normalizeFactor(transactions, period)
+	if err != nil {
+		fmt.Fprintf(w, "Per-%s rates: %v\n\n", period, err)
+		return
+	}
+	fmt.Fprintf(w, "%sPer-%s rates over a %d-day span (rates, not actuals):\n", glyph("📏 ", ""), period, span)
+	income, expenses := totalAmounts(transactions)
+	fmt.Fprintf(w, "  Income:   %10.2f/%s\n", income*factor, period)
+	fmt.Fprintf(w, "  Expenses: %10.2f/%s\n", -expenses*factor, period)
+	fmt.Fprintf(w, "  Net:      %10.2f/%s\n", (income+expenses)*factor, period)
+	totals := tagTotals(transactions)
+	tags := make([]string, 0, len(totals))
+	for tag := range totals {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		fmt.Fprintf(w, "  [%s] %.2f/%s\n", tag, totals[tag]*factor, period)
+	}
+	fmt.Fprintln(w)
+}
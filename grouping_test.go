@@ -0,0 +1,94 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeDayToMonth(t *testing.T) {
+	tests := []struct {
+		name    string
+		ledger  string
+		period  string
+		span    int
+		net     float64 // normalized net per period
+		wantErr bool
+	}{
+		{"ten days to a month", "# 2024-01-01\n+ 100 Pay [Income]\n# 2024-01-10\n- 40 Food [Food]\n", "month", 10, 60 * 365.25 / 12 / 10, false},
+		{"one day to a month", "# 2024-01-01\n- 10 Food [Food]\n", "month", 1, -10 * 365.25 / 12, false},
+		{"ten days to a week", "# 2024-01-01\n+ 100 Pay [Income]\n# 2024-01-10\n- 40 Food [Food]\n", "week", 10, 60 * 7.0 / 10, false},
+		{"no transactions", "", "month", 0, 0, true},
+		{"unknown period", "# 2024-01-01\n- 10 Food [Food]\n", "fortnight", 0, 0, true},
+	}
+	for _, tt := range tests {
+		txns := parseText(t, tt.ledger)
+		factor, span, err := normalizeFactor(txns, tt.period)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		income, expenses := totalAmounts(txns)
+		if span != tt.span || math.Abs((income+expenses)*factor-tt.net) > 1e-9 {
+			t.Errorf("%s: span %d, net %.4f; want %d, %.4f", tt.name, span, (income+expenses)*factor, tt.span, tt.net)
+		}
+	}
+}
+
+func TestCheckNormalizeTo(t *testing.T) {
+	for _, period := range []string{"day", "week", "month", "year"} {
+		if err := checkNormalizeTo(period); err != nil {
+			t.Errorf("checkNormalizeTo(%q): %v", period, err)
+		}
+	}
+	for _, period := range []string{"fortnight", "quarter", "Month", ""} {
+		if err := checkNormalizeTo(period); err == nil {
+			t.Errorf("checkNormalizeTo(%q): expected an error", period)
+		}
+	}
+}
+
+func TestPrintNormalizedLabelsRates(t *testing.T) {
+	txns := parseText(t, "# 2024-01-01\n+ 100 Pay [Income]\n# 2024-01-10\n- 40 Food [Food]\n")
+	var b strings.Builder
+	printNormalized(&b, txns, "month")
+	for _, want := range []string{
+		"Per-month rates over a 10-day span (rates, not actuals):\n",
+		"  Income:       304.38/month\n",
+		"  [Food] -121.75/month\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestPrintPeriodSubtotalsDayNotes(t *testing.T) {
+	defer func(n map[string][]string) { dayNotes = n }(dayNotes)
+	defer func(f, to string) { fromDate, toDate = f, to }(fromDate, toDate)
+
+	txns, notes, err := parseLedger(strings.NewReader("# 2024-01-01\n- 10 Food [Food]\n> Paid in cash\n# 2024-01-02\n> Stayed in\n# 2024-01-05\n> Later\n"), "test.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dayNotes, fromDate, toDate = notes, "", "2024-01-03"
+	var b strings.Builder
+	printPeriodSubtotals(&b, txns, "day")
+	out := b.String()
+	for _, want := range []string{
+		"  2024-01-01 Income:       0.00  Expenses:      10.00  Net:     -10.00\n      > Paid in cash\n",
+		"  2024-01-02 Income:       0.00  Expenses:       0.00  Net:       0.00\n      > Stayed in\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Later") {
+		t.Errorf("note after --to shown:\n%s", out)
+	}
+}
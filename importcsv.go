@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseCSV reads transactions from a CSV file whose header names its
+// columns: date (YYYY-MM-DD, optionally with " HH:MM"), amount (signed)
+// and description are required; tags (";"-separated) and projected are
+// optional, as written by --export-csv. Other columns are ignored. A bad
+// date or amount is an error naming the row.
+func parseCSV(filename string) ([]Transaction, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading header: %w", filename, err)
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "amount", "description"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("%s: missing %q column", filename, required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := col[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var out []Transaction
+	for row := 2; ; row++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filename, row, err)
+		}
+		date, err := time.Parse("2006-01-02", field(record, "date"))
+		if err != nil {
+			date, err = time.Parse("2006-01-02 15:04", field(record, "date"))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid date %q", filename, row, field(record, "date"))
+		}
+		amountText := strings.NewReplacer(",", "", "$", "").Replace(field(record, "amount"))
+		amount, err := strconv.ParseFloat(amountText, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid amount %q", filename, row, field(record, "amount"))
+		}
+		tags := []string{}
+		for _, tag := range strings.Split(field(record, "tags"), ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		var projected *float64
+		if p, err := strconv.ParseFloat(field(record, "projected"), 64); err == nil {
+			projected = &p
+		}
+		out = append(out, Transaction{
+			Date:            date,
+			Type:            map[bool]string{true: "income", false: "expense"}[amount >= 0],
+			Amount:          amount,
+			Description:     field(record, "description"),
+			Tags:            tags,
+			ProjectedAmount: projected,
+			Status:          "cleared",
+		})
+	}
+	return out, nil
+}
+
+// AutotagRule tags transactions whose description contains Keyword
+// (ignoring case).
+type AutotagRule struct {
+	Keyword string
+	Tags    []string
+	Matched int
+}
+
+// loadAutotagRules reads "keyword = Tag1, Tag2" lines; blank lines and
+// lines starting with "#" are ignored. Earlier rules take precedence.
+func loadAutotagRules(filename string) ([]AutotagRule, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []AutotagRule
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keyword, tags, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(keyword) == "" || len(splitList(tags)) == 0 {
+			return nil, fmt.Errorf("%s:%d: expected \"keyword = Tag\"", filename, lineNum)
+		}
+		rules = append(rules, AutotagRule{Keyword: strings.TrimSpace(keyword), Tags: splitList(tags)})
+	}
+	return rules, scanner.Err()
+}
+
+// autotag assigns tags to untagged transactions from the first rule whose
+// keyword appears in the description, falling back to defaultTag. Each
+// rule's Matched count is updated.
+func autotag(transactions []Transaction, rules []AutotagRule, defaultTag string) (out []Transaction, unmatched int) {
+	out = make([]Transaction, len(transactions))
+	for i, txn := range transactions {
+		if len(txn.Tags) == 0 {
+			matched := false
+			for r := range rules {
+				if strings.Contains(strings.ToLower(txn.Description), strings.ToLower(rules[r].Keyword)) {
+					txn.Tags = append([]string(nil), rules[r].Tags...)
+					rules[r].Matched++
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				unmatched++
+				if defaultTag != "" {
+					txn.Tags = []string{defaultTag}
+				}
+			}
+		}
+		out[i] = txn
+	}
+	return out, unmatched
+}
+
+// runImportCSV converts --import-csv to ledger markdown on stdout, tagged by
+// --autotag-rules. The per-rule report goes to stderr so the output can be
+// redirected straight into a ledger file.
+func runImportCSV() {
+	transactions, err := parseCSV(importCSV)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	var rules []AutotagRule
+	if autotagRules != "" {
+		rules, err = loadAutotagRules(autotagRules)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+	}
+	transactions, unmatched := autotag(transactions, rules, defaultTag)
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date.Before(transactions[j].Date)
+	})
+	fmt.Print(formatSource(transactions))
+
+	if len(rules) > 0 || defaultTag != "" {
+		fmt.Fprintf(os.Stderr, "Imported %d row(s) from %s:\n", len(transactions), importCSV)
+		for _, r := range rules {
+			fmt.Fprintf(os.Stderr, "  %-20s → [%s]: %d\n", r.Keyword, strings.Join(r.Tags, ", "), r.Matched)
+		}
+		label := "unmatched (untagged)"
+		if defaultTag != "" {
+			label = "unmatched → [" + defaultTag + "]"
+		}
+		fmt.Fprintf(os.Stderr, "  %s: %d\n", label, unmatched)
+	}
+}
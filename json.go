@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// SummaryJSON is the --summary-json output. Field names are part of the
+// tool's interface; add fields rather than renaming them.
+//
+//	income   total of positive amounts
+//	expense  total of negative amounts, as a positive number
+//	net      income - expense
+//	count    number of transactions after filtering
+//	from/to  earliest and latest transaction date (YYYY-MM-DD), null if none
+//	tags     net total per tag; untagged amounts are under "_untagged_"
+//	         unless --untagged-as names a tag for them; empty with --strip-tags
+type SummaryJSON struct {
+	Income  float64            `json:"income"`
+	Expense float64            `json:"expense"`
+	Net     float64            `json:"net"`
+	Count   int                `json:"count"`
+	From    *string            `json:"from"`
+	To      *string            `json:"to"`
+	Tags    map[string]float64 `json:"tags"`
+}
+
+func buildSummaryJSON(transactions []Transaction) SummaryJSON {
+	income, expenses := totalAmounts(transactions)
+	out := SummaryJSON{
+		Income:  income,
+		Expense: abs(expenses),
+		Net:     income + expenses,
+		Count:   len(transactions),
+		Tags:    tagTotals(transactions),
+	}
+	if stripTags {
+		out.Tags = map[string]float64{}
+	}
+	for i, txn := range transactions {
+		d := txn.Date.Format("2006-01-02")
+		if i == 0 || d < *out.From {
+			out.From = &d
+		}
+		if i == 0 || d > *out.To {
+			out.To = &d
+		}
+	}
+	return out
+}
+
+func printSummaryJSON(transactions []Transaction) error {
+	return json.NewEncoder(os.Stdout).Encode(buildSummaryJSON(transactions))
+}
+
+// TransactionJSON is the exported form of a Transaction. ProjectedAmount is
+// always present, as null when the transaction has no inline projection.
+type TransactionJSON struct {
+	Date            string   `json:"date"`
+	Type            string   `json:"type"`
+	Amount          float64  `json:"amount"`
+	Description     string   `json:"description"`
+	Tags            []string `json:"tags"`
+	ProjectedAmount *float64 `json:"projected_amount"`
+}
+
+func toTransactionJSON(txn Transaction) TransactionJSON {
+	tags := txn.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return TransactionJSON{
+		Date:            txn.Date.Format("2006-01-02"),
+		Type:            txn.Type,
+		Amount:          txn.Amount,
+		Description:     txn.Description,
+		Tags:            tags,
+		ProjectedAmount: txn.ProjectedAmount,
+	}
+}
+
+// SplitJSON is the --json-split-sign document: income and expenses as
+// separate arrays plus the aggregate summary.
+type SplitJSON struct {
+	Income   []TransactionJSON `json:"income"`
+	Expenses []TransactionJSON `json:"expenses"`
+	Summary  SummaryJSON       `json:"summary"`
+}
+
+// exportTransactionsJSON writes txns as an indented JSON array, or as a
+// SplitJSON document when --json-split-sign is set.
+func exportTransactionsJSON(txns []Transaction, filename string) error {
+	var doc interface{}
+	if jsonSplitSign {
+		split := SplitJSON{
+			Income:   []TransactionJSON{},
+			Expenses: []TransactionJSON{},
+			Summary:  buildSummaryJSON(txns),
+		}
+		for _, txn := range txns {
+			if txn.Amount >= 0 {
+				split.Income = append(split.Income, toTransactionJSON(txn))
+			} else {
+				split.Expenses = append(split.Expenses, toTransactionJSON(txn))
+			}
+		}
+		doc = split
+	} else {
+		flat := make([]TransactionJSON, 0, len(txns))
+		for _, txn := range txns {
+			flat = append(flat, toTransactionJSON(txn))
+		}
+		doc = flat
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
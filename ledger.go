@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ledgerAccount maps a transaction to a ledger-cli account named after its
+// primary (first) tag, under Expenses or Income depending on its sign.
+func ledgerAccount(txn Transaction) string {
+	root := "Income"
+	if txn.Amount < 0 {
+		root = "Expenses"
+	}
+	name := "Uncategorized"
+	if len(txn.Tags) > 0 {
+		name = strings.ReplaceAll(txn.Tags[0], ":", "-")
+	}
+	return root + ":" + name
+}
+
+// exportLedger writes transactions in ledger-cli / hledger journal format:
+// one entry per transaction with a posting to its tag account and a
+// balancing posting to --default-account.
+func exportLedger(transactions []Transaction, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := func(format string, args ...interface{}) {
+		fmt.Fprintf(f, format, args...)
+	}
+
+	for _, txn := range transactions {
+		w("%s %s\n", txn.Date.Format("2006/01/02"), txn.Description)
+		if len(txn.Tags) > 1 {
+			w("    ; Tags: %s\n", strings.Join(txn.Tags, ", "))
+		}
+		w("    %-38s  %s\n", ledgerAccount(txn), ledgerAmount(-txn.Amount))
+		w("    %-38s  %s\n", defaultAccount, ledgerAmount(txn.Amount))
+		w("\n")
+	}
+
+	return nil
+}
+
+func ledgerAmount(v float64) string {
+	if v < 0 {
+		return fmt.Sprintf("-%s%.2f", currencySymbol, -v)
+	}
+	return fmt.Sprintf("%s%.2f", currencySymbol, v)
+}
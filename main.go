@@ -4,7 +4,10 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -18,128 +21,1225 @@ type Transaction struct {
 	Amount          float64
 	Description     string
 	Tags            []string
-	ProjectedAmount *float64 // nil if not specified
+	ProjectedAmount *float64    // nil if not specified
+	Currency        string      // empty means the base currency
+	Installments    int         // >1 when paid over that many months ("installments:N")
+	Status          string      // "cleared" (default, or "*" after the sign) or "pending" ("!")
+	Components      []Component // set when one line carries several signed amounts
 }
 
 // CLI flags
 var (
 	filterTag      string
+	filterTagAll   string
 	filterType     string
 	fromDate       string
 	toDate         string
 	removeTags     string
 	adjustTags     string
 	exportMarkdown string
+	exportAppend   bool
 	file           string
+
+	assertNoWarnings bool
+	hashtags         bool
+	plain            bool
+
+	flagOutliers      bool
+	outlierMultiplier float64
+
+	netWorthFiles string
+	balanceAsOf   string
+
+	cooccurrence bool
+	topN         int
+
+	histogram bool
+	buckets   string
+
+	rates       string
+	tagCurrency string
+
+	projectionCap    float64
+	projectionCapPct float64
+	projectionFloor  bool
+	allowSignFlip    string
+
+	groupBy         string
+	fiscalYearStart int
+	showNetTrend    bool
+
+	highImpact bool
+	pareto     float64
+
+	colorByImpact bool
+
+	runway          bool
+	startingBalance float64
+
+	sectionTags bool
+
+	clampNegativeIncome bool
+
+	exportOFXFile string
+
+	exportLedgerFile string
+	defaultAccount   string
+	currencySymbol   string
+	baseCurrency     string
+
+	summaryJSON bool
+
+	hasProjection bool
+	noProjection  bool
+
+	reconcileFile string
+	tolerance     float64
+
+	baseTag string
+
+	exportJSON    string
+	jsonSplitSign bool
+
+	exportCSVFile     string
+	csvRunningBalance bool
+
+	inflation         float64
+	inflationBaseDate string
+
+	pivot string
+
+	maxLineBytes int
+
+	exportPerTagDir string
+
+	lint         bool
+	warnUnsorted bool
+
+	ratesFile string
+
+	templateFile string
+
+	subscriptions         bool
+	subscriptionTolerance float64
+	expectedCadence       string
+
+	excludeFrom string
+	excludeTo   string
+
+	exportSourceFile string
+
+	fmtMode  bool
+	fmtWrite bool
+	fmtCheck bool
+
+	budget  string
+	prorate bool
+
+	minChange    float64
+	minChangePct float64
+
+	primaryTagOnly bool
+
+	dir       string
+	recursive bool
+
+	pendingOnly bool
+	clearedOnly bool
+
+	interest       string
+	interestMonths int
+	forecastMonths int
+
+	weeklyAllowance float64
+	allowanceTags   string
+
+	roundWhole bool
+
+	where string
+
+	dedupeTags    bool
+	tagDistance   int
+	applyTagMerge bool
+
+	newSinceFile string
+
+	strict bool
+
+	priorFile string
+
+	whatIf stringList
+
+	monthlyCap float64
+	failOnCap  bool
+
+	requireTags           bool
+	allowUntaggedDescribe string
+
+	sensitivity      bool
+	sensitivityDelta float64
+
+	goal          float64
+	goalDate      string
+	savingsWindow int
+
+	dropCrossFileDupes bool
+
+	emojiSet string
+
+	minTransactions int
+
+	colorLegend bool
+
+	importCSV    string
+	autotagRules string
+	defaultTag   string
+
+	budgetTrend bool
+
+	tolerant bool
+
+	largestChange bool
+
+	weekdayWeekend bool
+
+	byFirstWord bool
+
+	listTags       bool
+	listTagsCounts bool
+	listTagsSort   string
+
+	percentiles      bool
+	percentilesByTag bool
+
+	forecastAverage string
+
+	tagTimelineReport bool
+
+	netTarget float64
+
+	stripTags bool
+
+	envelope string
+
+	tagLimit    string
+	limitBuffer float64
+
+	untaggedAs string
+
+	growth bool
+
+	explainNet bool
+
+	minAmount float64
+	maxAmount float64
+
+	focusTags string
+
+	normalizeTo string
+
+	diffFile string
+	diffMode string
 )
 
 func init() {
-	flag.StringVar(&filterTag, "tag", "", "Filter transactions by tag")
+	flag.StringVar(&filterTag, "tag", "", "Filter transactions by tag; comma-separate tags to keep transactions with any of them (OR)")
+	flag.StringVar(&filterTagAll, "tag-all", "", "Keep only transactions carrying every one of these comma-separated tags (AND; combines with --tag)")
 	flag.StringVar(&filterType, "type", "", "Filter by type: income or expense")
+	flag.Float64Var(&minAmount, "min-amount", 0, "Keep only transactions of at least this absolute amount (0 disables)")
+	flag.Float64Var(&maxAmount, "max-amount", 0, "Keep only transactions of at most this absolute amount (0 disables)")
 	flag.StringVar(&fromDate, "from", "", "Start date YYYY-MM-DD")
 	flag.StringVar(&toDate, "to", "", "End date YYYY-MM-DD")
 	flag.StringVar(&removeTags, "remove", "", "Comma-separated tags to remove")
 	flag.StringVar(&adjustTags, "adjust", "", "Tag adjustments e.g. Food=-0.5,Salary=0.1")
 	flag.StringVar(&exportMarkdown, "export-md", "", "Export side-by-side projection as a Markdown file")
-	flag.StringVar(&file, "file", "sample-cashflow.md", "Cashflow markdown file to process")
+	flag.BoolVar(&exportAppend, "export-append", false, "Append Markdown exports to the file as a timestamped section instead of overwriting it")
+	flag.StringVar(&file, "file", "sample-cashflow.md", "Cashflow file to process: Markdown, or CSV when it ends in .csv (comma-separate several to combine them)")
+	flag.BoolVar(&hashtags, "hashtags", false, "Also treat #word tokens in descriptions as tags")
+	flag.BoolVar(&plain, "plain", false, "Plain console output without emoji decoration")
+	flag.BoolVar(&flagOutliers, "flag-outliers", false, "Mark expenses that are unusually large for their tag")
+	flag.Float64Var(&outlierMultiplier, "outlier-multiplier", 3, "How many times the tag's average expense counts as an outlier")
+	flag.StringVar(&netWorthFiles, "net-worth", "", "Comma-separated account files to combine into a net-worth snapshot")
+	flag.StringVar(&balanceAsOf, "balance-as-of", "", "Only count transactions up to this date YYYY-MM-DD")
+	flag.BoolVar(&cooccurrence, "cooccurrence", false, "Report which tags appear together on the same transaction")
+	flag.IntVar(&topN, "top", 10, "Maximum rows in ranked reports (0 for all)")
+	flag.BoolVar(&histogram, "histogram", false, "Show a histogram of expense amounts")
+	flag.StringVar(&buckets, "buckets", "10,50,100,500", "Comma-separated histogram bucket boundaries")
+	flag.StringVar(&rates, "rates", "", "Currency rates into the base currency e.g. EUR=1.08,GBP=1.27")
+	flag.StringVar(&tagCurrency, "tag-currency", "", "Currency of tags that are foreign-denominated e.g. Travel=EUR")
+	flag.Float64Var(&projectionCap, "projection-cap", 0, "Cap any projected transaction's absolute amount at this value (0 disables)")
+	flag.Float64Var(&projectionCapPct, "projection-cap-pct", 0, "Cap projected amounts at this fraction above the original e.g. 0.5 (0 disables)")
+	flag.BoolVar(&projectionFloor, "projection-floor", false, "Clamp projected amounts at zero instead of letting an adjustment flip income into expense or back")
+	flag.StringVar(&allowSignFlip, "allow-sign-flip", "", "With --projection-floor, comma-separated tags whose projected amounts may still change sign")
+	flag.StringVar(&groupBy, "group-by", "", "Print subtotals per period: day, week, month, quarter or year; add \",tag\" (e.g. month,tag) to nest tag totals")
+	flag.IntVar(&fiscalYearStart, "fiscal-year-start", 1, "Month (1-12) the fiscal year starts in, for quarters")
+	flag.BoolVar(&showNetTrend, "show-net-trend", false, "Show each period's net change versus the previous period")
+	flag.BoolVar(&highImpact, "high-impact", false, "List the expense tags with the biggest share of spending")
+	flag.Float64Var(&pareto, "pareto", 0, "List high-impact tags until they make up this share of expenses e.g. 0.8")
+	flag.BoolVar(&colorByImpact, "color-by-impact", false, "Color tag totals by sign and magnitude (terminal only; honours NO_COLOR)")
+	flag.BoolVar(&runway, "runway", false, "Report average monthly burn and months of runway left")
+	flag.Float64Var(&startingBalance, "starting-balance", 0, "Opening account balance before the first transaction")
+	flag.BoolVar(&sectionTags, "section-tags", false, "Tag untagged transactions with the enclosing ## or ### heading")
+	flag.BoolVar(&clampNegativeIncome, "clamp-negative-income", false, "Flip amounts whose sign contradicts their +/- marker instead of failing")
+	flag.StringVar(&exportOFXFile, "export-ofx", "", "Export the filtered transactions as an OFX statement")
+	flag.StringVar(&exportLedgerFile, "export-ledger", "", "Export the filtered transactions as a ledger-cli journal")
+	flag.StringVar(&defaultAccount, "default-account", "Assets:Checking", "Balancing account for --export-ledger")
+	flag.StringVar(&currencySymbol, "currency-symbol", "$", "Currency symbol used in exported amounts")
+	flag.StringVar(&baseCurrency, "base-currency", "USD", "ISO 4217 code of the ledger's base currency, amounts in it need no --rates entry; written by --export-ofx")
+	flag.BoolVar(&summaryJSON, "summary-json", false, "Print only the aggregate totals as JSON")
+	flag.BoolVar(&hasProjection, "has-projection", false, "Keep only transactions with an inline (…) projected amount")
+	flag.BoolVar(&noProjection, "no-projection", false, "Keep only transactions without an inline (…) projected amount")
+	flag.StringVar(&reconcileFile, "reconcile", "", "Statement file to reconcile the filtered transactions against")
+	flag.Float64Var(&tolerance, "tolerance", 0.01, "Largest amount difference still treated as a match when reconciling")
+	flag.StringVar(&baseTag, "base-tag", "", "Show tag totals as a percentage of this tag's total e.g. Salary")
+	flag.StringVar(&exportJSON, "export-json", "", "Export the filtered transactions as JSON")
+	flag.BoolVar(&jsonSplitSign, "json-split-sign", false, "With --export-json, write {income, expenses, summary} instead of a flat array")
+	flag.StringVar(&exportCSVFile, "export-csv", "", "Export the filtered transactions as CSV")
+	flag.BoolVar(&csvRunningBalance, "csv-running-balance", false, "With --export-csv, sort rows by date and add a running balance column")
+	flag.Float64Var(&inflation, "inflation", 0, "Annual inflation rate applied in projections e.g. 0.03")
+	flag.StringVar(&inflationBaseDate, "inflation-base-date", "", "Date YYYY-MM-DD inflation is measured from (default: earliest transaction)")
+	flag.StringVar(&pivot, "pivot", "", "Print a cross-tab of tags against: type")
+	flag.IntVar(&maxLineBytes, "max-line-bytes", 1<<20, "Skip (with a warning) input lines longer than this many bytes")
+	flag.StringVar(&exportPerTagDir, "export-per-tag", "", "Export one Markdown projection per tag into this directory")
+	flag.BoolVar(&lint, "lint", false, "Check the ledger for problems, print them and exit (non-zero if any)")
+	flag.BoolVar(&warnUnsorted, "warn-unsorted", false, "Warn when a date header is earlier than the one before it")
+	flag.StringVar(&ratesFile, "rates-file", "", "CSV of date,currency,rate rows; each transaction uses the latest rate on or before its date")
+	flag.StringVar(&templateFile, "template", "", "Render the summary with this text/template file (\"default\" for the built-in layout)")
+	flag.BoolVar(&subscriptions, "subscriptions", false, "Detect recurring monthly charges and their annual cost")
+	flag.Float64Var(&subscriptionTolerance, "subscription-tolerance", 0.1, "Allowed amount variance for --subscriptions, as a fraction of the average")
+	flag.StringVar(&expectedCadence, "expected-cadence", "", "Flag same-description transactions closer together than this e.g. 25d or 4w (narrow with --tag or --where)")
+	flag.StringVar(&excludeFrom, "exclude-from", "", "Drop transactions from this date YYYY-MM-DD (inclusive)")
+	flag.StringVar(&excludeTo, "exclude-to", "", "Drop transactions up to this date YYYY-MM-DD (inclusive)")
+	flag.StringVar(&exportSourceFile, "export-source", "", "Re-emit the filtered transactions in the ledger's own Markdown format")
+	flag.BoolVar(&fmtMode, "fmt", false, "Print the ledger file in canonical format")
+	flag.BoolVar(&fmtWrite, "write", false, "With --fmt, rewrite the file in place")
+	flag.BoolVar(&fmtCheck, "check", false, "With --fmt, exit non-zero if the file is not already formatted")
+	flag.StringVar(&budget, "budget", "", "Monthly budgets per tag for a budget-vs-actual report e.g. Food=400,Fun=150")
+	flag.BoolVar(&prorate, "prorate", false, "Scale budgets to the part of each month the data covers")
+	flag.Float64Var(&minChange, "min-change", 0, "Only report tag changes of at least this absolute amount")
+	flag.Float64Var(&minChangePct, "min-change-pct", 0, "Only report tag changes of at least this fraction of the original e.g. 0.1")
+	flag.BoolVar(&primaryTagOnly, "primary-tag-only", false, "Attribute each amount only to its first tag in totals (filters still match any tag)")
+	flag.StringVar(&dir, "dir", "", "Aggregate every *.md ledger in this directory instead of --file")
+	flag.BoolVar(&recursive, "recursive", false, "With --dir, include subdirectories")
+	flag.BoolVar(&pendingOnly, "pending", false, "Keep only pending transactions (marked \"!\" after the sign)")
+	flag.BoolVar(&clearedOnly, "cleared", false, "Keep only cleared transactions")
+	flag.StringVar(&interest, "interest", "", "Annual interest rates compounded monthly over --interest-months e.g. Savings=0.04,Loan=0.19")
+	flag.IntVar(&interestMonths, "interest-months", 0, "Months --interest compounds over (defaults to --forecast)")
+	flag.IntVar(&forecastMonths, "forecast", 0, "Forecast horizon in months; also the --interest horizon when --interest-months is unset")
+	flag.Float64Var(&weeklyAllowance, "weekly-allowance", 0, "Flag ISO weeks whose spending on --allowance-tags exceeds this amount")
+	flag.StringVar(&allowanceTags, "allowance-tags", "", "Comma-separated tags counted against --weekly-allowance (default: all expenses)")
+	flag.BoolVar(&roundWhole, "round-whole", false, "Display amounts rounded to whole units")
+	flag.StringVar(&where, "where", "", "Filter expression e.g. \"tag=Food and amount>50 and date>=2024-01-01\"")
+	flag.BoolVar(&dedupeTags, "dedupe-tags-global", false, "List near-duplicate tags and the merges they suggest")
+	flag.IntVar(&tagDistance, "tag-distance", 2, "Maximum edit distance for tags to count as near-duplicates")
+	flag.BoolVar(&applyTagMerge, "apply-tag-merges", false, "Merge near-duplicate tags into their most used spelling")
+	flag.StringVar(&newSinceFile, "new-since", "", "Report tags that do not appear in this prior ledger file")
+	flag.BoolVar(&strict, "strict", false, "Treat amounts with more decimals than their currency allows, and lines --tolerant would repair, as errors")
+	flag.StringVar(&priorFile, "prior", "", "Use the closing balance of this prior-period ledger as --starting-balance")
+	flag.Var(&whatIf, "what-if", "Hypothetical transaction line to compare against, e.g. \"-1200 Laptop [Electronics]\" (repeatable; may start with a YYYY-MM-DD date)")
+	flag.Float64Var(&monthlyCap, "monthly-cap", 0, "Flag months whose total expenses exceed this amount")
+	flag.BoolVar(&failOnCap, "fail-on-cap", false, "Exit non-zero when a month exceeds --monthly-cap")
+	flag.BoolVar(&requireTags, "require-tags", false, "Report untagged transactions and exit non-zero if there are any")
+	flag.StringVar(&allowUntaggedDescribe, "allow-untagged-describe", "", "Comma-separated description fragments exempt from --require-tags")
+	flag.BoolVar(&sensitivity, "sensitivity", false, "Show how net changes when each expense tag moves by --sensitivity-delta")
+	flag.Float64Var(&sensitivityDelta, "sensitivity-delta", 0.1, "Fractional change applied per tag by --sensitivity")
+	flag.Float64Var(&goal, "goal", 0, "Savings target; reports progress and weeks remaining")
+	flag.StringVar(&goalDate, "goal-date", "", "Target date for --goal (YYYY-MM-DD); shows the weekly savings needed")
+	flag.IntVar(&savingsWindow, "savings-window", 8, "Trailing weeks used to average the savings rate for --goal")
+	flag.BoolVar(&dropCrossFileDupes, "drop-cross-file-dupes", false, "With --dir or a comma-separated --file, keep only the first file's copy of a transaction found in several files")
+	flag.StringVar(&emojiSet, "emoji-set", "default", "Icon set: default, ascii, and/or overrides like summary=>>,tags=#")
+	flag.IntVar(&minTransactions, "min-transactions", 0, "Suppress statistical sections when fewer transactions than this remain after filtering")
+	flag.BoolVar(&colorLegend, "color-legend", false, "Explain the tag category labels (and colours) under the tag totals")
+	flag.StringVar(&importCSV, "import-csv", "", "Convert a bank CSV (date,amount,description[,tags]) to ledger markdown on stdout")
+	flag.StringVar(&autotagRules, "autotag-rules", "", "File of \"keyword = Tag\" rules that tag --import-csv rows by description")
+	flag.StringVar(&defaultTag, "default-tag", "", "Tag for --import-csv rows that no autotag rule matches")
+	flag.BoolVar(&budgetTrend, "budget-trend", false, "With --budget, show each tag's monthly over/under series and trend")
+	flag.BoolVar(&tolerant, "tolerant", false, "Recover transactions from lines with an unclosed [ or unbalanced ( (an error under --strict)")
+	flag.BoolVar(&explainNet, "explain-net", false, "Break the projected change in net down by the tag driving it")
+	flag.BoolVar(&largestChange, "largest-change", false, "List the transactions whose projected amount changes most (up to --top)")
+	flag.BoolVar(&weekdayWeekend, "weekday-weekend", false, "Compare weekday and weekend spending per day")
+	flag.BoolVar(&byFirstWord, "by-first-word", false, "Rank expenses by the first word of their description (a rough merchant view)")
+	flag.BoolVar(&percentiles, "percentiles", false, "Show p25/p50/p75/p90 of expense amounts")
+	flag.BoolVar(&percentilesByTag, "percentiles-by-tag", false, "With --percentiles, also break them down per tag")
+	flag.StringVar(&forecastAverage, "forecast-average", "span", "Per-tag monthly average for --forecast: span (all months in range) or active (months the tag appears in)")
+	flag.BoolVar(&tagTimelineReport, "tag-timeline", false, "Show each tag's first and last use, active months and total (also added to --export-md)")
+	flag.Float64Var(&netTarget, "net-target", 0, "Monthly net savings target compared against each month in --group-by month")
+	flag.BoolVar(&stripTags, "strip-tags", false, "Leave tags out of exported files (totals are kept)")
+	flag.BoolVar(&growth, "growth", false, "Rank expense tags by the trend of their monthly spending, fastest growing first")
+	flag.StringVar(&untaggedAs, "untagged-as", "", "Tag given to transactions without tags, instead of reporting them as _untagged_")
+	flag.StringVar(&tagLimit, "tag-limit", "", "Warn when a tag's running balance nears or exceeds its limit e.g. CreditCard=5000")
+	flag.Float64Var(&limitBuffer, "limit-buffer", 0, "With --tag-limit, also warn once a balance is within this amount of its limit")
+	flag.StringVar(&envelope, "envelope", "", "Envelope budgets per tag e.g. Food=400,Fun=150 (refilled monthly with --group-by month)")
+	flag.StringVar(&focusTags, "focus-tags", "", "Show grand totals plus detailed stats for only these comma-separated tags")
+	flag.StringVar(&normalizeTo, "normalize-to", "", "Restate totals as rates per day, week, month or year over the data's span")
+	flag.StringVar(&diffFile, "diff", "", "Compare the ledger with another ledger file")
+	flag.StringVar(&diffMode, "diff-mode", "transactions", "What --diff compares: transactions or tags (per-tag totals)")
+	flag.BoolVar(&listTags, "list-tags", false, "Print every distinct tag (lowercased) and exit")
+	flag.BoolVar(&listTagsCounts, "list-tags-counts", false, "With --list-tags, show how many transactions carry each tag")
+	flag.StringVar(&listTagsSort, "list-tags-sort", "name", "Order for --list-tags: name or count")
+	flag.BoolVar(&assertNoWarnings, "assert-no-warnings", false, "Print any parse/lint warnings and exit non-zero if there were some")
+}
+
+// warnings collects every problem noticed while parsing or linting so
+// they can be reported together (and gated on in CI).
+var warnings []string
+
+// dayNotes holds the main input's "> note" lines by the date
+// ("2006-01-02") they sit under; other parsed files keep their own.
+var dayNotes = map[string][]string{}
+
+// untaggedCount counts the --require-tags violations among warnings.
+var untaggedCount int
+
+func warnf(format string, args ...interface{}) {
+	warnings = append(warnings, fmt.Sprintf(format, args...))
+}
+
+var strayProjectionRegex = regexp.MustCompile(`\s*[\d.]*\)$`)
+
+// repairLine guesses at a malformed transaction line for --tolerant: an
+// unclosed "[" runs to the end of the line, and an unbalanced "(" or ")"
+// projection is dropped. A ")" that ends no projection, such as a smiley in
+// the description, is left alone. It returns the repaired line and what was
+// assumed, or "" when nothing was changed.
+func repairLine(line string) (string, string) {
+	var assumed []string
+	if open := strings.LastIndex(line, "("); strings.Count(line, "(") > strings.Count(line, ")") {
+		line = strings.TrimSpace(line[:open])
+		assumed = append(assumed, "unbalanced ( so the projection was ignored")
+	} else if strings.Count(line, ")") > strings.Count(line, "(") {
+		if fixed := strayProjectionRegex.ReplaceAllString(line, ""); fixed != line {
+			line = fixed
+			assumed = append(assumed, "unbalanced ) so the projection was ignored")
+		}
+	}
+	if strings.Count(line, "[") > strings.Count(line, "]") {
+		line += "]"
+		assumed = append(assumed, "unclosed [ so tags were taken to the end of the line")
+	}
+	return line, strings.Join(assumed, "; ")
+}
+
+// withoutTags returns copies of transactions with every tag removed.
+func withoutTags(transactions []Transaction) []Transaction {
+	out := make([]Transaction, len(transactions))
+	for i, txn := range transactions {
+		txn.Tags = nil
+		if len(txn.Components) > 0 {
+			components := make([]Component, len(txn.Components))
+			for j, c := range txn.Components {
+				c.Tags = nil
+				components[j] = c
+			}
+			txn.Components = components
+		}
+		out[i] = txn
+	}
+	return out
+}
+
+// untaggedAllowed reports whether a description matches one of the
+// --allow-untagged-describe fragments.
+func untaggedAllowed(description string) bool {
+	for _, fragment := range splitList(allowUntaggedDescribe) {
+		if strings.Contains(strings.ToLower(description), strings.ToLower(fragment)) {
+			return true
+		}
+	}
+	return false
+}
+
+// glyph picks the decorated or the plain variant of a piece of console output.
+func glyph(fancy, plainAlt string) string {
+	if plain {
+		return plainAlt
+	}
+	return customGlyph(fancy)
+}
+
+// printWarnings lists the collected warnings on w: stdout when they are
+// the report (--lint), stderr when they accompany one.
+func printWarnings(w io.Writer) {
+	fmt.Fprintf(w, "%s%d warning(s):\n", glyph("⚠️  ", ""), len(warnings))
+	for _, warning := range warnings {
+		fmt.Fprintln(w, "  "+warning)
+	}
 }
 
 func main() {
 	flag.Parse()
 
-	transactions, err := parseSimpleMarkdown(file)
-	if err != nil {
-		fmt.Println("Error:", err)
+	if err := loadEmojiSet(emojiSet); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if groupBy != "" {
+		if err := checkGroupBy(groupBy); err != nil {
+			fmt.Println("Invalid --group-by:", err)
+			os.Exit(1)
+		}
+	}
+	if normalizeTo != "" {
+		if err := checkNormalizeTo(normalizeTo); err != nil {
+			fmt.Println("Invalid --normalize-to:", err)
+			os.Exit(1)
+		}
+	}
+
+	if importCSV != "" {
+		runImportCSV()
+		return
+	}
+
+	if netWorthFiles != "" {
+		var asOf time.Time
+		if balanceAsOf != "" {
+			var err error
+			asOf, err = time.Parse("2006-01-02", balanceAsOf)
+			if err != nil {
+				fmt.Println("Invalid --balance-as-of date format")
+				os.Exit(1)
+			}
+		}
+		printNetWorth(splitList(netWorthFiles), asOf)
+		return
+	}
+
+	if priorFile != "" {
+		carryForward(priorFile)
+	}
+
+	var transactions []Transaction
+	var err error
+	if dir != "" {
+		files, err := ledgerFiles(dir, recursive)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		transactions, dayNotes = parseFiles(files)
+	} else if files := splitList(file); len(files) > 1 {
+		transactions, dayNotes = parseFiles(files)
+	} else {
+		transactions, dayNotes, err = parseFile(file)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+	}
+
+	if fmtMode {
+		if dir != "" || len(splitList(file)) > 1 || strings.EqualFold(filepath.Ext(file), ".csv") {
+			fmt.Println("--fmt works on a single Markdown --file, not --dir or CSV")
+			os.Exit(1)
+		}
+		runFmt(file, transactions, dayNotes)
+		return
+	}
+
+	sortByDate(transactions)
+	transactions = applyUntaggedAs(transactions)
+
+	if rates != "" || ratesFile != "" || tagCurrency != "" || hasForeignCurrency(transactions) {
+		table := RateTable{Flat: map[string]float64{}}
+		for cur, rate := range parseAdjustments(rates) {
+			table.Flat[strings.ToUpper(cur)] = rate
+		}
+		if ratesFile != "" {
+			table.Dated, err = loadRatesFile(ratesFile)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+		}
+		transactions = convertCurrencies(transactions, table, parseTagCurrencies(tagCurrency))
+	}
+
+	if lint {
+		if len(warnings) == 0 {
+			fmt.Println(glyph("✅ ", "") + "No problems found")
+			return
+		}
+		printWarnings(os.Stdout)
+		os.Exit(1)
+	}
+
+	if assertNoWarnings && len(warnings) > 0 {
+		printWarnings(os.Stderr)
+		os.Exit(1)
+	}
+
+	if requireTags && untaggedCount > 0 {
+		printWarnings(os.Stderr)
+		os.Exit(1)
+	}
+
+	// Anything the parser recovered from or noticed is worth knowing even
+	// when it did not stop the run.
+	if len(warnings) > 0 {
+		printWarnings(os.Stderr)
+		fmt.Fprintln(os.Stderr)
+	}
+
+	if interest != "" && interestHorizon() <= 0 {
+		fmt.Println("--interest requires an --interest-months or --forecast horizon in months")
+		os.Exit(1)
+	}
+
+	var tagMerges []TagMerge
+	if dedupeTags || applyTagMerge {
+		tagMerges = findTagMerges(transactions, tagDistance)
+		if applyTagMerge {
+			transactions = applyTagMerges(transactions, tagMerges)
+		}
+	}
+
+	if listTags || listTagsCounts {
+		if err := printTagList(transactions, listTagsCounts, listTagsSort); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 		return
 	}
 
 	transactions = applyFilters(transactions)
-	printSummary(transactions)
+
+	if reconcileFile != "" {
+		statement, _, err := parseFile(reconcileFile)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		printReconciliation(reconcile(transactions, statement, tolerance))
+		return
+	}
+
+	if diffFile != "" {
+		other, _, err := parseFile(diffFile)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		printDiff(transactions, applyFilters(applyUntaggedAs(other)), diffFile, diffMode)
+		return
+	}
+
+	if summaryJSON {
+		if err := printSummaryJSON(transactions); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if templateFile != "" {
+		if err := printTemplateSummary(transactions, templateFile); err != nil {
+			fmt.Println("Error rendering template:", err)
+			os.Exit(1)
+		}
+	} else {
+		printSummary(transactions)
+	}
+
+	if dedupeTags {
+		printTagMerges(tagMerges)
+	}
+	if newSinceFile != "" {
+		prior, _, err := parseFile(newSinceFile)
+		if err != nil {
+			fmt.Println("Error reading", newSinceFile+":", err)
+			os.Exit(1)
+		}
+		printNewTags(transactions, applyUntaggedAs(prior), newSinceFile)
+	}
+	if (highImpact || pareto > 0) && focusTags == "" && sufficientData(transactions, "High-impact tags") {
+		printHighImpactTags(transactions)
+	}
+	if cooccurrence {
+		printCooccurrence(transactions)
+	}
+	if weekdayWeekend {
+		printWeekdayWeekend(transactions)
+	}
+	if byFirstWord {
+		printByFirstWord(transactions)
+	}
+	if runway && sufficientData(transactions, "Runway") {
+		printRunway(transactions)
+	}
+	if subscriptions && sufficientData(transactions, "Subscriptions") {
+		printSubscriptions(transactions)
+	}
+	if expectedCadence != "" {
+		if err := printCadenceViolations(transactions, expectedCadence); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if budget != "" {
+		printBudget(transactions, parseAdjustments(budget))
+		if budgetTrend {
+			printBudgetTrend(transactions, parseAdjustments(budget))
+		}
+	}
+	if envelope != "" {
+		printEnvelopes(transactions, parseAdjustments(envelope))
+	}
+	if tagLimit != "" {
+		printTagLimits(transactions, parseAdjustments(tagLimit), limitBuffer)
+	}
+	if weeklyAllowance > 0 {
+		printWeeklyAllowance(transactions, weeklyAllowance, splitList(allowanceTags))
+	}
+	if goal > 0 && sufficientData(transactions, "Savings goal") {
+		printGoal(transactions)
+	}
+	if sensitivity && sufficientData(transactions, "Sensitivity") {
+		printSensitivity(transactions, sensitivityDelta)
+	}
+	capExceeded := false
+	if monthlyCap > 0 {
+		capExceeded = printMonthlyCap(transactions, monthlyCap) > 0
+	}
+	switch pivot {
+	case "":
+	case "type":
+		printPivot(transactions)
+	default:
+		fmt.Printf("Unknown --pivot %q (supported: type)\n", pivot)
+		os.Exit(1)
+	}
+	if forecastMonths > 0 && sufficientData(transactions, "Forecast") {
+		printForecast(transactions, forecastMonths, forecastAverage)
+	}
+	if tagTimelineReport {
+		printTagTimeline(transactions)
+	}
+	if growth && sufficientData(transactions, "Growth") {
+		printGrowth(transactions)
+	}
+	if percentiles || percentilesByTag {
+		printPercentiles(transactions)
+	}
+	if histogram {
+		bounds, err := parseBuckets(buckets)
+		if err != nil {
+			fmt.Println("Invalid --buckets:", err)
+			os.Exit(1)
+		}
+		printHistogram(transactions, bounds)
+	}
+
+	if len(whatIf) > 0 {
+		injected, err := parseWhatIf(whatIf)
+		if err != nil {
+			fmt.Println("Invalid --what-if:", err)
+			os.Exit(1)
+		}
+		printSideBySide(Projection{
+			Original:  transactions,
+			Projected: append(append([]Transaction{}, transactions...), injected...),
+			Title:     "What-If (Baseline → With " + strings.Join(whatIf, ", ") + ")",
+		})
+	}
 
 	projection := buildProjection(transactions, adjustTags)
 	printSideBySide(projection)
+	if largestChange {
+		printLargestChanges(projection)
+	}
+	if explainNet {
+		printExplainNet(projection)
+	}
 
 	if exportMarkdown != "" {
 		err := exportProjectionMarkdown(projection, exportMarkdown)
 		if err != nil {
 			fmt.Println("Error writing markdown:", err)
 		} else {
-			fmt.Println("📁 Exported projection to:", exportMarkdown)
+			fmt.Println(glyph("📁 ", "")+"Exported projection to:", exportMarkdown)
+		}
+	}
+
+	// --strip-tags keeps categories out of everything exported below.
+	exported := transactions
+	if stripTags {
+		exported = withoutTags(transactions)
+	}
+
+	if exportPerTagDir != "" && stripTags {
+		fmt.Println("Skipping --export-per-tag: it cannot be written with --strip-tags")
+	} else if exportPerTagDir != "" {
+		n, err := exportPerTag(transactions, adjustTags, exportPerTagDir)
+		if err != nil {
+			fmt.Println("Error writing per-tag exports:", err)
+		} else {
+			fmt.Printf("%sExported %d tag file(s) to: %s\n", glyph("📁 ", ""), n, exportPerTagDir)
+		}
+	}
+
+	if exportJSON != "" {
+		err := exportTransactionsJSON(exported, exportJSON)
+		if err != nil {
+			fmt.Println("Error writing JSON:", err)
+		} else {
+			fmt.Println(glyph("📁 ", "")+"Exported JSON to:", exportJSON)
+		}
+	}
+
+	if exportCSVFile != "" {
+		err := exportCSV(exported, exportCSVFile)
+		if err != nil {
+			fmt.Println("Error writing CSV:", err)
+		} else {
+			fmt.Println(glyph("📁 ", "")+"Exported CSV to:", exportCSVFile)
+		}
+	}
+
+	if exportSourceFile != "" {
+		err := exportSource(exported, exportSourceFile)
+		if err != nil {
+			fmt.Println("Error writing source:", err)
+		} else {
+			fmt.Println(glyph("📁 ", "")+"Exported source to:", exportSourceFile)
+		}
+	}
+
+	if exportOFXFile != "" {
+		err := exportOFX(exported, exportOFXFile)
+		if err != nil {
+			fmt.Println("Error writing OFX:", err)
+		} else {
+			fmt.Println(glyph("📁 ", "")+"Exported OFX to:", exportOFXFile)
 		}
 	}
 
+	if exportLedgerFile != "" {
+		err := exportLedger(exported, exportLedgerFile)
+		if err != nil {
+			fmt.Println("Error writing ledger:", err)
+		} else {
+			fmt.Println(glyph("📁 ", "")+"Exported ledger journal to:", exportLedgerFile)
+		}
+	}
+
+	if failOnCap && capExceeded {
+		os.Exit(1)
+	}
 }
 
-func parseSimpleMarkdown(filename string) ([]Transaction, error) {
+func parseSimpleMarkdown(filename string) ([]Transaction, map[string][]string, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer file.Close()
+	return parseLedger(file, filename)
+}
+
+// parseFile reads transactions from a .csv file with parseCSV, or from any
+// other file as a Markdown ledger along with its notes (see parseLedger).
+func parseFile(filename string) ([]Transaction, map[string][]string, error) {
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		txns, err := parseCSV(filename)
+		return txns, nil, err
+	}
+	return parseSimpleMarkdown(filename)
+}
 
+// parseLedger parses ledger text from r; filename only labels warnings.
+// Alongside the transactions it returns the "> note" lines by the date
+// ("2006-01-02") they sit under.
+func parseLedger(r io.Reader, filename string) ([]Transaction, map[string][]string, error) {
 	var transactions []Transaction
+	notes := map[string][]string{}
 	var currentDate time.Time
 
-	scanner := bufio.NewScanner(file)
+	reader := bufio.NewReader(r)
+	lineNum := 0
+	today := time.Now()
 
-	dateRegex := regexp.MustCompile(`^#\s+(\d{4}-\d{2}-\d{2})$`)
+	// A date header may carry a time of day: "# 2024-02-01 14:30".
+	dateRegex := regexp.MustCompile(`^#\s+(\d{4}-\d{2}-\d{2}(?:\s+\d{1,2}:\d{2})?)$`)
+	// A transaction line may start with its own time: "14:30 - 9.49 Coffee".
+	lineTimeRegex := regexp.MustCompile(`^(\d{1,2}):(\d{2})\s+([+-].*)$`)
+	sectionRegex := regexp.MustCompile(`^(#{2,3})\s+(.+)$`)
 	// Matches: - 9.49 Coffee [Tag1, Tag2] (5.20)
-	txnRegex := regexp.MustCompile(`^([+-])\s*([\d.]+)\s+(.+?)(?:\s+\[([^\]]+)\])?(?:\s+\(([\d.]+)\))?$`)
+	// The description is optional, so "- 20 [Cash]" and "- 20" parse too.
+	// A currency code may follow the amount: "- 50EUR" or "- 50 EUR Dinner";
+	// it must be exactly three uppercase letters, so "- 50 RENT" stays a
+	// description, and a known currency (see knownCurrency), so the ATM in
+	// "- 40 ATM withdrawal" does too. A "*" (cleared) or "!" (pending) may follow the sign.
+	// A balance assertion: "= 1500.00" checks the running balance so far.
+	assertRegex := regexp.MustCompile(`^=\s*(-?[\d.]+)$`)
+	continuationRegex := regexp.MustCompile(`^(.*?)(?:\s*\[([^\]]*)\])?(?:\s+\(([\d.]+)\))?$`)
+	txnRegex := regexp.MustCompile(`^([+-])([*!])?\s*([\d.]+)(?:\s*([A-Z]{3})\b)?(?:\s+(.+?))??(?:\s+\[([^\]]+)\])?(?:\s+\(([\d.]+)\))?$`)
+
+	inFrontmatter := false
+	var lastDate time.Time
+	lastDateLine := 0
+	// Headings seen under the current date, indexed by level (## and ###).
+	var section [2]string
+	balance := startingBalance
+	continues := false // the previous line was a transaction
+	untaggedWarning := -1
+
+	// tagList splits a bracketed "A, B" tag list, warning about and
+	// dropping a repeated tag.
+	tagList := func(list string) []string {
+		tags := []string{}
+		for _, tag := range strings.Split(list, ",") {
+			if tag = strings.TrimSpace(tag); tag == "" {
+				continue
+			}
+			if hasTag(Transaction{Tags: tags}, tag) {
+				warnf("%s:%d: duplicate tag %q", filename, lineNum, tag)
+				continue
+			}
+			tags = append(tags, tag)
+		}
+		return tags
+	}
+
+	for {
+		raw, tooLong, err := readLine(reader, maxLineBytes)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		lineNum++
+		if tooLong {
+			warnf("%s:%d: line longer than %d bytes; skipped", filename, lineNum, maxLineBytes)
+			continue
+		}
+		line := strings.TrimSpace(raw)
+
+		// Skip a leading "---" frontmatter block (see readFrontmatter),
+		// except that its starting_balance seeds this file's assertions.
+		if lineNum == 1 && line == "---" {
+			inFrontmatter = true
+			continue
+		}
+		if inFrontmatter {
+			if line == "---" {
+				inFrontmatter = false
+			}
+			if key, value, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(key) == "starting_balance" {
+				balance, err = strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%s:%d: invalid starting_balance %q", filename, lineNum, strings.TrimSpace(value))
+				}
+			}
+			continue
+		}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
+			continues = false
+			continue
+		}
+
+		// A continuation line is indented and starts with no marker
+		// (+, -, =, #) or HH:MM time; it extends the preceding transaction's
+		// description and may carry the tags and projection the wrapped line
+		// would have.
+		if continues && (raw[0] == ' ' || raw[0] == '\t') && !strings.ContainsRune("+-=#>", rune(line[0])) && !lineTimeRegex.MatchString(line) {
+			last := &transactions[len(transactions)-1]
+			m := continuationRegex.FindStringSubmatch(line)
+			text, added := m[1], tagList(m[2])
+			if hashtags {
+				var extra []string
+				text, extra = extractHashtags(text)
+				added = mergeTags(added, extra)
+			}
+			last.Description = strings.TrimSpace(last.Description + " " + text)
+			last.Tags = mergeTags(last.Tags, added)
+			if len(last.Tags) > 0 && untaggedWarning >= 0 && untaggedWarning == len(warnings)-1 {
+				// The wrapped line supplied the tags after all.
+				warnings = warnings[:untaggedWarning]
+				untaggedCount--
+				untaggedWarning = -1
+			}
+			if p, err := strconv.ParseFloat(m[3], 64); err == nil && last.ProjectedAmount == nil {
+				last.ProjectedAmount = &p
+			}
+			if len(last.Components) > 0 {
+				last.Components[0].Description = last.Description
+				last.Components[0].Tags = mergeTags(last.Components[0].Tags, added)
+			}
+			continue
+		}
+		continues = false
+		untaggedWarning = -1
+
+		if matches := dateRegex.FindStringSubmatch(line); len(matches) == 2 {
+			layout := "2006-01-02"
+			if strings.Contains(matches[1], ":") {
+				matches[1] = strings.Join(strings.Fields(matches[1]), " ")
+				layout = "2006-01-02 15:04"
+			}
+			date, err := time.Parse(layout, matches[1])
+			if err != nil {
+				warnf("%s:%d: invalid date %q", filename, lineNum, matches[1])
+				continue
+			}
+			if date.After(today) {
+				warnf("%s:%d: date %s is in the future", filename, lineNum, matches[1])
+			}
+			if (lint || warnUnsorted) && date.Before(lastDate) {
+				warnf("%s:%d: date %s is earlier than %s on line %d", filename, lineNum, matches[1], lastDate.Format("2006-01-02"), lastDateLine)
+			}
+			lastDate, lastDateLine = date, lineNum
+			currentDate = date
+			section = [2]string{}
+			continue
+		}
+
+		if matches := sectionRegex.FindStringSubmatch(line); sectionTags && matches != nil {
+			if len(matches[1]) == 2 {
+				section = [2]string{strings.TrimSpace(matches[2]), ""}
+			} else {
+				section[1] = strings.TrimSpace(matches[2])
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, ">") {
+			if currentDate.IsZero() {
+				warnf("%s:%d: note before any date header; ignored", filename, lineNum)
+				continue
+			}
+			key := currentDate.Format("2006-01-02")
+			notes[key] = append(notes[key], strings.TrimSpace(line[1:]))
+			continue
+		}
+
+		if matches := assertRegex.FindStringSubmatch(line); matches != nil {
+			want, err := strconv.ParseFloat(matches[1], 64)
+			if err != nil {
+				warnf("%s:%d: invalid balance assertion %q", filename, lineNum, line)
+				continue
+			}
+			if math.Abs(balance-want) >= 0.005 {
+				return nil, nil, fmt.Errorf("%s:%d: balance assertion failed: expected %.2f, running balance is %.2f (off by %+.2f)", filename, lineNum, want, balance, balance-want)
+			}
+			if fmtMode {
+				warnf("%s:%d: balance assertion is not kept by --fmt", filename, lineNum)
+			}
+			continue
+		}
+
+		txnDate := currentDate
+		if m := lineTimeRegex.FindStringSubmatch(line); m != nil && !currentDate.IsZero() {
+			hour, _ := strconv.Atoi(m[1])
+			minute, _ := strconv.Atoi(m[2])
+			if hour > 23 || minute > 59 {
+				warnf("%s:%d: invalid time %s:%s", filename, lineNum, m[1], m[2])
+			} else {
+				txnDate = dayOf(currentDate).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+				line = m[3]
+			}
+		}
+
+		mainLine, extras := splitComponents(line)
+		if tolerant && txnRegex.MatchString(mainLine) {
+			if fixed, assumed := repairLine(mainLine); assumed != "" {
+				if strict {
+					return nil, nil, fmt.Errorf("%s:%d: malformed line (without --strict: %s)", filename, lineNum, assumed)
+				}
+				warnf("%s:%d: recovered malformed line: %s", filename, lineNum, assumed)
+				mainLine = fixed
+			}
+		}
+		if matches := txnRegex.FindStringSubmatch(mainLine); len(matches) >= 3 {
+			sign := matches[1]
+			status := "cleared"
+			if matches[2] == "!" {
+				status = "pending"
+			}
+			amount, err := strconv.ParseFloat(matches[3], 64)
+			if err != nil {
+				continue
+			}
+			if sign == "-" {
+				amount = -amount
+			}
+
+			currency := matches[4]
+			description := strings.TrimSpace(matches[5])
+			if currency != "" && !knownCurrency(currency) {
+				description = strings.TrimSpace(currency + " " + description)
+				currency = ""
+			}
+			if excessPrecision(matches[3], currency) {
+				digits := currencyPrecision(currency)
+				err := fmt.Errorf("%s:%d: amount %s has more than %d decimal places (rounds to %.*f)", filename, lineNum, matches[3], digits, digits, abs(amount))
+				if strict {
+					return nil, nil, err
+				}
+				warnings = append(warnings, err.Error())
+			}
+			tags := tagList(matches[6])
+
+			installments := 0
+			if m := installmentsRegex.FindStringSubmatch(description); m != nil {
+				installments, _ = strconv.Atoi(m[1])
+				description = strings.Join(strings.Fields(installmentsRegex.ReplaceAllString(description, "")), " ")
+			}
+
+			if len(tags) == 0 {
+				if section[1] != "" {
+					tags = []string{section[1]}
+				} else if section[0] != "" {
+					tags = []string{section[0]}
+				}
+			}
+
+			if hashtags {
+				var extra []string
+				description, extra = extractHashtags(description)
+				tags = mergeTags(tags, extra)
+			}
+
+			var projectedAmount *float64
+			if len(matches) >= 8 && matches[7] != "" {
+				p, err := strconv.ParseFloat(matches[7], 64)
+				if err == nil {
+					projectedAmount = &p
+				}
+			}
+
+			var components []Component
+			if len(extras) > 0 {
+				components = append([]Component{{Amount: amount, Description: description, Tags: tags}}, extras...)
+				for _, c := range extras {
+					amount += c.Amount
+					tags = mergeTags(tags, c.Tags)
+				}
+			}
+			// Components can outweigh the line's own amount, e.g.
+			// "+ 100 Salary [Income] - 500 Fee [Bank]" nets to -400.
+			if (sign == "+" && amount < 0) || (sign == "-" && amount > 0) {
+				if !clampNegativeIncome {
+					return nil, nil, fmt.Errorf("%s:%d: amount %.2f contradicts its %q marker", filename, lineNum, amount, sign)
+				}
+				warnf("%s:%d: amount %.2f contradicts its %q marker; flipped", filename, lineNum, amount, sign)
+				amount = -amount
+				for i := range components {
+					components[i].Amount = -components[i].Amount
+				}
+			}
+
+			if requireTags && len(tags) == 0 && !untaggedAllowed(description) {
+				warnf("%s:%d: untagged transaction %q", filename, lineNum, description)
+				untaggedCount++
+				untaggedWarning = len(warnings) - 1
+			}
+
+			balance += amount
+			transactions = append(transactions, Transaction{
+				Date:            txnDate,
+				Type:            map[bool]string{true: "income", false: "expense"}[amount >= 0],
+				Amount:          amount,
+				Description:     description,
+				Tags:            tags,
+				ProjectedAmount: projectedAmount,
+				Currency:        currency,
+				Installments:    installments,
+				Status:          status,
+				Components:      components,
+			})
+			continues = true
 			continue
 		}
 
-		if matches := dateRegex.FindStringSubmatch(line); len(matches) == 2 {
-			date, err := time.Parse("2006-01-02", matches[1])
-			if err == nil {
-				currentDate = date
-			}
+		warnf("%s:%d: unparsed line: %q", filename, lineNum, line)
+	}
+
+	return transactions, notes, nil
+}
+
+// readLine reads one line of any length, reporting tooLong (and returning
+// no text) when it exceeds max bytes instead of failing like bufio.Scanner.
+func readLine(r *bufio.Reader, max int) (line string, tooLong bool, err error) {
+	var buf []byte
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return "", false, err
+		}
+		if !tooLong {
+			buf = append(buf, chunk...)
+			if max > 0 && len(buf) > max {
+				tooLong = true
+				buf = nil
+			}
+		}
+		if !isPrefix {
+			return string(buf), tooLong, nil
+		}
+	}
+}
+
+// rawFrontmatter returns the lines of a leading block delimited by "---"
+// lines, delimiters included, or nil if the file has none.
+func rawFrontmatter(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "---" {
+		return nil, scanner.Err()
+	}
+	lines := []string{"---"}
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if strings.TrimSpace(scanner.Text()) == "---" {
+			break
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// readFrontmatter returns the "key: value" pairs of a leading block
+// delimited by "---" lines, e.g. account name and starting_balance.
+func readFrontmatter(filename string) (map[string]string, error) {
+	lines, err := rawFrontmatter(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]string{}
+	for _, line := range lines {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
 			continue
 		}
+		out[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return out, nil
+}
 
-		if matches := txnRegex.FindStringSubmatch(line); len(matches) >= 3 {
-			sign := matches[1]
-			amount, err := strconv.ParseFloat(matches[2], 64)
-			if err != nil {
-				continue
-			}
-			if sign == "-" {
-				amount = -amount
-			}
-
-			description := strings.TrimSpace(matches[3])
-			tags := []string{}
-			if len(matches) >= 5 && matches[4] != "" {
-				tags = strings.Split(matches[4], ",")
-				for i := range tags {
-					tags[i] = strings.TrimSpace(tags[i])
-				}
-			}
+var installmentsRegex = regexp.MustCompile(`(?:^|\s)installments:(\d+)\b`)
 
-			var projectedAmount *float64
-			if len(matches) >= 6 && matches[5] != "" {
-				p, err := strconv.ParseFloat(matches[5], 64)
-				if err == nil {
-					projectedAmount = &p
-				}
-			}
+var hashtagRegex = regexp.MustCompile(`^#(\pL[\pL\pN_-]*)$`)
 
-			transactions = append(transactions, Transaction{
-				Date:            currentDate,
-				Type:            map[bool]string{true: "income", false: "expense"}[amount >= 0],
-				Amount:          amount,
-				Description:     description,
-				Tags:            tags,
-				ProjectedAmount: projectedAmount,
-			})
+// extractHashtags pulls standalone #word tokens out of a description.
+// A lone "#" or a "#" in the middle of a word (C#, item#3) is left alone.
+func extractHashtags(description string) (string, []string) {
+	var words, tags []string
+	for _, word := range strings.Fields(description) {
+		if m := hashtagRegex.FindStringSubmatch(word); m != nil {
+			tags = append(tags, m[1])
+			continue
 		}
-
+		words = append(words, word)
 	}
-
-	return transactions, scanner.Err()
+	return strings.Join(words, " "), tags
 }
 
 func applyFilters(transactions []Transaction) []Transaction {
@@ -162,24 +1262,88 @@ func applyFilters(transactions []Transaction) []Transaction {
 		}
 	}
 
+	var exFrom, exTo time.Time
+	if excludeFrom != "" {
+		exFrom, err = time.Parse("2006-01-02", excludeFrom)
+		if err != nil {
+			fmt.Println("Invalid --exclude-from date format")
+			os.Exit(1)
+		}
+	}
+	if excludeTo != "" {
+		exTo, err = time.Parse("2006-01-02", excludeTo)
+		if err != nil {
+			fmt.Println("Invalid --exclude-to date format")
+			os.Exit(1)
+		}
+	}
+	excluding := !exFrom.IsZero() || !exTo.IsZero()
+
+	var wherePred func(Transaction) bool
+	if where != "" {
+		wherePred, err = compileWhere(where)
+		if err != nil {
+			fmt.Println("Invalid --where:", err)
+			os.Exit(1)
+		}
+	}
+
+	if hasProjection && noProjection {
+		fmt.Println("--has-projection and --no-projection are mutually exclusive")
+		os.Exit(1)
+	}
+	if minAmount > 0 && maxAmount > 0 && minAmount > maxAmount {
+		fmt.Printf("--min-amount %g is greater than --max-amount %g\n", minAmount, maxAmount)
+		os.Exit(1)
+	}
+
 	// ✅ Parse remove tags once
 	removeSet := parseRemovals(removeTags)
+	tagSet := parseRemovals(filterTag)
+	allTags := splitList(filterTagAll)
 
 	for _, txn := range transactions {
 		// ✅ Skip if any tag matches remove set
 		if hasAnyTag(txn, removeSet) {
 			continue
 		}
-		if filterTag != "" && !hasTag(txn, filterTag) {
+		if len(tagSet) > 0 && !hasAnyTag(txn, tagSet) {
+			continue
+		}
+		if !hasAllTags(txn, allTags) {
 			continue
 		}
 		if filterType != "" && !strings.EqualFold(txn.Type, filterType) {
 			continue
 		}
+		if minAmount > 0 && abs(txn.Amount) < minAmount {
+			continue
+		}
+		if maxAmount > 0 && abs(txn.Amount) > maxAmount {
+			continue
+		}
 		if !from.IsZero() && txn.Date.Before(from) {
 			continue
 		}
-		if !to.IsZero() && txn.Date.After(to) {
+		if !to.IsZero() && dayOf(txn.Date).After(to) {
+			continue
+		}
+		if excluding && (exFrom.IsZero() || !txn.Date.Before(exFrom)) && (exTo.IsZero() || !dayOf(txn.Date).After(exTo)) {
+			continue
+		}
+		if wherePred != nil && !wherePred(txn) {
+			continue
+		}
+		if pendingOnly && txn.Status != "pending" {
+			continue
+		}
+		if clearedOnly && txn.Status == "pending" {
+			continue
+		}
+		if hasProjection && txn.ProjectedAmount == nil {
+			continue
+		}
+		if noProjection && txn.ProjectedAmount != nil {
 			continue
 		}
 		result = append(result, txn)
@@ -197,93 +1361,334 @@ func hasTag(txn Transaction, tag string) bool {
 	return false
 }
 
+// mergeTags appends the tags in extra that tags lacks, ignoring case. It
+// never writes into tags' backing array, which a component may share.
+func mergeTags(tags, extra []string) []string {
+	tags = tags[:len(tags):len(tags)]
+	for _, tag := range extra {
+		if !hasTag(Transaction{Tags: tags}, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// hasAllTags reports whether txn carries every tag in tags, ignoring case.
+func hasAllTags(txn Transaction, tags []string) bool {
+	for _, tag := range tags {
+		if !hasTag(txn, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// dataShortfall explains why there are too few transactions for
+// statistics under --min-transactions, or returns "" when there are enough.
+func dataShortfall(transactions []Transaction) string {
+	if len(transactions) >= minTransactions {
+		return ""
+	}
+	return fmt.Sprintf("insufficient data (have %d, need %d)", len(transactions), minTransactions)
+}
+
+// sufficientData reports whether a statistical section may print, noting
+// the shortfall in its place when it may not.
+func sufficientData(transactions []Transaction, section string) bool {
+	if note := dataShortfall(transactions); note != "" {
+		fmt.Printf("%s: %s\n\n", section, note)
+		return false
+	}
+	return true
+}
+
+// dateRange returns the earliest and latest days with transactions.
+func dateRange(transactions []Transaction) (first, last time.Time, ok bool) {
+	for i, txn := range transactions {
+		if i == 0 || txn.Date.Before(first) {
+			first = txn.Date
+		}
+		if i == 0 || txn.Date.After(last) {
+			last = txn.Date
+		}
+	}
+	return dayOf(first), dayOf(last), len(transactions) > 0
+}
+
+// sortByDate orders txns by date and time, keeping file order for ties.
+func sortByDate(txns []Transaction) {
+	sort.SliceStable(txns, func(i, j int) bool {
+		return txns[i].Date.Before(txns[j].Date)
+	})
+}
+
+// dayOf drops the time of day from t.
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// dateLabel formats t as a date, adding the time of day when one was given.
+func dateLabel(t time.Time) string {
+	if t.Equal(dayOf(t)) {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// sameDay reports whether a and b fall on the same calendar day.
+func sameDay(a, b time.Time) bool {
+	return dayOf(a).Equal(dayOf(b))
+}
+
+// printSummary prints the standard summary by rendering
+// defaultSummaryTemplate, so --template default always matches it.
 func printSummary(transactions []Transaction) {
-	fmt.Println("📊 Filtered Cash Flow Summary:")
-	var incomeTotal, expenseTotal float64
-	for _, txn := range transactions {
-		fmt.Printf("%s [%s] %.2f - %s %v\n",
-			txn.Date.Format("2006-01-02"),
-			txn.Type,
-			txn.Amount,
-			txn.Description,
-			txn.Tags,
-		)
-		if txn.Amount >= 0 {
-			incomeTotal += txn.Amount
-		} else {
-			expenseTotal += txn.Amount
+	if err := printTemplateSummary(transactions, "default"); err != nil {
+		fmt.Println("Error rendering summary:", err)
+		os.Exit(1)
+	}
+}
+
+// TagStat aggregates the transactions carrying a single tag.
+type TagStat struct {
+	Count int
+	Total float64
+}
+
+func (s TagStat) Average() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / float64(s.Count)
+}
+
+// summaryTags returns the tags a transaction's amount is attributed to in
+// totals: all of them, only the first with --primary-tag-only, or
+// "_untagged_" when it has none.
+func summaryTags(txn Transaction) []string {
+	if len(txn.Tags) == 0 {
+		return []string{"_untagged_"}
+	}
+	if primaryTagOnly {
+		return txn.Tags[:1]
+	}
+	return txn.Tags
+}
+
+// applyUntaggedAs gives every untagged transaction (or untagged leading
+// component) the --untagged-as tag, so filters and reports treat it like
+// any other tag. The ledger file itself is left alone.
+func applyUntaggedAs(transactions []Transaction) []Transaction {
+	if untaggedAs == "" {
+		return transactions
+	}
+	for i, txn := range transactions {
+		if len(txn.Tags) == 0 {
+			transactions[i].Tags = []string{untaggedAs}
+		}
+		if len(txn.Components) > 0 && len(txn.Components[0].Tags) == 0 {
+			components := append([]Component(nil), txn.Components...)
+			components[0].Tags = []string{untaggedAs}
+			transactions[i].Components = components
+			if !hasTag(transactions[i], untaggedAs) {
+				transactions[i].Tags = append(append([]string(nil), transactions[i].Tags...), untaggedAs)
+			}
 		}
 	}
+	return transactions
+}
 
-	fmt.Printf("\nTotal Income:  %.2f\n", incomeTotal)
-	fmt.Printf("Total Expenses: %.2f\n", -expenseTotal)
-	fmt.Printf("Net:            %.2f\n\n", incomeTotal+expenseTotal)
+func tagStats(transactions []Transaction) map[string]TagStat {
+	out := map[string]TagStat{}
+	for _, txn := range transactions {
+		for tag, amount := range tagAmounts(txn) {
+			st := out[tag]
+			st.Count++
+			st.Total += amount
+			out[tag] = st
+		}
+	}
+	return out
+}
 
-	printTagSummary(transactions)
+// averageExpenseByTag returns the average expense magnitude for every tag.
+func averageExpenseByTag(transactions []Transaction) map[string]float64 {
+	var expenses []Transaction
+	for _, txn := range transactions {
+		if txn.Amount < 0 {
+			expenses = append(expenses, txn)
+		}
+	}
+	out := map[string]float64{}
+	for tag, st := range tagStats(expenses) {
+		out[tag] = -st.Average()
+	}
+	return out
+}
 
-	fmt.Println()
+func isOutlier(txn Transaction, averages map[string]float64) bool {
+	if txn.Amount >= 0 {
+		return false
+	}
+	for _, tag := range summaryTags(txn) {
+		if avg := averages[tag]; avg > 0 && -txn.Amount > outlierMultiplier*avg {
+			return true
+		}
+	}
+	return false
 }
 
-func printTagSummary(transactions []Transaction) {
+func printTagSummary(w io.Writer, transactions []Transaction) {
 	tagSums := make(map[string]float64)
 
 	for _, txn := range transactions {
-		if len(txn.Tags) == 0 {
-			tagSums["_untagged_"] += txn.Amount
-		} else {
-			for _, tag := range txn.Tags {
-				tagSums[tag] += txn.Amount
-			}
+		for tag, amount := range tagAmounts(txn) {
+			tagSums[tag] += amount
 		}
 	}
 
-	fmt.Println("📌 Totals by Tag:")
+	fmt.Fprintln(w, glyph("📌 ", "")+"Totals by Tag:")
 	keys := make([]string, 0, len(tagSums))
 	for tag := range tagSums {
 		keys = append(keys, tag)
 	}
 	sort.Strings(keys)
 
+	var maxAbs float64
+	for _, total := range tagSums {
+		maxAbs = max(maxAbs, abs(total))
+	}
+
+	base, hasBase := baseTagTotal(w, transactions)
+
+	splits := tagSignSplit(transactions)
 	for _, tag := range keys {
 		total := tagSums[tag]
-		category := "Income"
-		if total < 0 {
-			category = "Expense"
+		split := splits[tag]
+		share := ""
+		if hasBase {
+			share = fmt.Sprintf(" (%.1f%% of %s)", abs(total)/base*100, baseTag)
+		}
+		parts := ""
+		if split.Category() == "Mixed" {
+			parts = fmt.Sprintf(" (+%.2f / -%.2f)", split.Income, abs(split.Expense))
+		}
+		fmt.Fprintf(w, "  [%s] %s: %s%s%s\n", tag, split.Category(), colorByMagnitude(fmt.Sprintf("%.2f", total), total, maxAbs), parts, share)
+	}
+
+	if colorLegend {
+		fmt.Fprintln(w, "\n  Income-only: only income · Expense-only: only expenses · Mixed: both (net shown, then +income / -expenses)")
+		if useColor() {
+			fmt.Fprintln(w, "  Colour: green net positive, red net negative; bold is large, faint is small")
+		}
+	}
+}
+
+// baseTagTotal returns the absolute total of --base-tag, the denominator for
+// relative percentages. It reports false (after printing why) when the flag
+// is unset or the tag is missing or sums to zero.
+func baseTagTotal(w io.Writer, transactions []Transaction) (float64, bool) {
+	if baseTag == "" {
+		return 0, false
+	}
+	var total float64
+	found := false
+	for tag, sum := range tagTotals(transactions) {
+		if strings.EqualFold(tag, baseTag) {
+			total += sum
+			found = true
 		}
-		fmt.Printf("  [%s] %s: %.2f\n", tag, category, total)
 	}
+	if !found || total == 0 {
+		fmt.Fprintf(w, "  (base tag %q is missing or zero; percentages omitted)\n", baseTag)
+		return 0, false
+	}
+	return abs(total), true
 }
 
 type Projection struct {
 	Original  []Transaction
 	Projected []Transaction
 	AdjustMap map[string]float64
+	Reasons   []string // why each projected amount differs; "" if unchanged
+	Capped    int      // transactions limited by --projection-cap/--projection-cap-pct
+	Floored   int      // transactions clamped at zero by --projection-floor
+	Title     string   // heading for printSideBySide; empty for the default
+	ShowDelta bool     // printSideBySide appends each tag's change
 }
 
+// reasonInline marks a projection taken from an inline "(…)" amount.
+const reasonInline = "inline"
+
+// buildProjection derives a projected amount for every transaction. An
+// inline "(…)" amount wins outright; otherwise the first matching tag
+// adjustment is applied, then --inflation compounds the result by the
+// years between the transaction and the inflation base date, then an
+// --interest rate for the transaction's tag compounds it monthly over the
+// --forecast horizon. Caps from --projection-cap are applied last, after
+// --projection-floor stops an amount from crossing zero.
 func buildProjection(original []Transaction, adjust string) Projection {
 	adjustMap := parseAdjustments(adjust)
+	interestMap := parseAdjustments(interest)
+	inflationBase := inflationBaseTime(original)
+	flipTags := parseRemovals(allowSignFlip)
+	months := interestHorizon()
 
 	var projected []Transaction
+	var reasons []string
+	capped, floored := 0, 0
 
 	for _, txn := range original {
 
 		adjustedTxn := txn
+		reason := ""
 
 		// If an inline projected amount is given, use it directly
 		if txn.ProjectedAmount != nil {
 			// Preserve original sign
 			adjustedTxn.Amount = float64(signum(txn.Amount)) * (*txn.ProjectedAmount)
+			reason = reasonInline
 		} else {
 			// Apply tag-based adjustment
 			for _, tag := range txn.Tags {
 				if adj, ok := adjustMap[tag]; ok {
 					adjustedTxn.Amount *= (1.0 + adj)
+					reason = fmt.Sprintf("%s=%+g", tag, adj)
+					break
+				}
+			}
+
+			if years := txn.Date.Sub(inflationBase).Hours() / 24 / 365.25; inflation != 0 && years != 0 {
+				adjustedTxn.Amount *= math.Pow(1+inflation, years)
+				reason = strings.TrimSpace(reason + fmt.Sprintf(" inflation %+g/yr", inflation))
+			}
+
+			for _, tag := range txn.Tags {
+				if rate, ok := interestMap[tag]; ok && months > 0 {
+					adjustedTxn.Amount *= math.Pow(1+rate/12, float64(months))
+					reason = strings.TrimSpace(reason + fmt.Sprintf(" interest %s %+g/yr over %dmo", tag, rate, months))
 					break
 				}
 			}
 		}
 
+		if projectionFloor && adjustedTxn.Amount != 0 && txn.Amount != 0 && (adjustedTxn.Amount > 0) != (txn.Amount > 0) && !hasAnyTag(txn, flipTags) {
+			adjustedTxn.Amount = 0
+			reason = strings.TrimSpace(reason + " (floored)")
+			floored++
+		}
+
+		if limit, ok := projectionLimit(txn.Amount); ok && abs(adjustedTxn.Amount) > limit {
+			adjustedTxn.Amount = float64(signum(adjustedTxn.Amount)) * limit
+			reason = strings.TrimSpace(reason + " (capped)")
+			capped++
+		}
+
+		if txn.Amount != 0 {
+			adjustedTxn = scaleComponents(adjustedTxn, adjustedTxn.Amount/txn.Amount)
+		}
 		projected = append(projected, adjustedTxn)
+		reasons = append(reasons, reason)
 
 	}
 
@@ -291,7 +1696,57 @@ func buildProjection(original []Transaction, adjust string) Projection {
 		Original:  original,
 		Projected: projected,
 		AdjustMap: adjustMap,
+		Reasons:   reasons,
+		Capped:    capped,
+		Floored:   floored,
+	}
+}
+
+// interestHorizon is how many months --interest compounds over:
+// --interest-months, or the --forecast horizon when that is unset.
+func interestHorizon() int {
+	if interestMonths > 0 {
+		return interestMonths
+	}
+	return forecastMonths
+}
+
+// inflationBaseTime resolves --inflation-base-date, defaulting to the
+// earliest transaction date.
+func inflationBaseTime(transactions []Transaction) time.Time {
+	if inflationBaseDate != "" {
+		base, err := time.Parse("2006-01-02", inflationBaseDate)
+		if err != nil {
+			fmt.Println("Invalid --inflation-base-date format")
+			os.Exit(1)
+		}
+		return base
+	}
+	var base time.Time
+	for i, txn := range transactions {
+		if i == 0 || txn.Date.Before(base) {
+			base = txn.Date
+		}
+	}
+	return base
+}
+
+// projectionLimit returns the largest absolute projected amount allowed for
+// a transaction whose original amount is orig, if any cap is configured:
+// --projection-cap, or --projection-cap-pct above the original, whichever
+// is lower.
+func projectionLimit(orig float64) (float64, bool) {
+	limit, ok := 0.0, false
+	if projectionCap > 0 {
+		limit, ok = projectionCap, true
 	}
+	if projectionCapPct > 0 {
+		pctLimit := abs(orig) * (1 + projectionCapPct)
+		if !ok || pctLimit < limit {
+			limit, ok = pctLimit, true
+		}
+	}
+	return limit, ok
 }
 
 func parseAdjustments(s string) map[string]float64 {
@@ -323,6 +1778,17 @@ func parseRemovals(s string) map[string]bool {
 	return out
 }
 
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 func hasAnyTag(txn Transaction, tagSet map[string]bool) bool {
 	for _, tag := range txn.Tags {
 		if tagSet[strings.ToLower(tag)] {
@@ -333,18 +1799,30 @@ func hasAnyTag(txn Transaction, tagSet map[string]bool) bool {
 }
 
 func printSideBySide(p Projection) {
-	fmt.Println("📊 Side-by-Side Summary (Original → Projected)")
+	title := p.Title
+	if title == "" {
+		title = "Side-by-Side Summary (Original → Projected)"
+	}
+	fmt.Println(glyph("📊 ", "") + title)
 
 	origIncome, origExpense := totalAmounts(p.Original)
 	projIncome, projExpense := totalAmounts(p.Projected)
 
-	fmt.Printf("\n  Income:    %8.2f  →  %8.2f\n", origIncome, projIncome)
-	fmt.Printf("  Expenses:  %8.2f  →  %8.2f\n", -origExpense, -projExpense)
-	fmt.Printf("  Net:       %8.2f  →  %8.2f\n\n", origIncome+origExpense, projIncome+projExpense)
+	fmt.Printf("\n  Income:    %8s  →  %8s\n", money(origIncome), money(projIncome))
+	fmt.Printf("  Expenses:  %8s  →  %8s\n", money(-origExpense), money(-projExpense))
+	fmt.Printf("  Net:       %8s  →  %8s\n\n", money(origIncome+origExpense), money(projIncome+projExpense))
 
-	fmt.Println("🔍 Tag Changes:")
+	if p.Capped > 0 {
+		fmt.Printf("  (%d transaction(s) capped by --projection-cap)\n\n", p.Capped)
+	}
+	if p.Floored > 0 {
+		fmt.Printf("  (%d transaction(s) clamped at zero by --projection-floor)\n\n", p.Floored)
+	}
+
+	fmt.Println(glyph("🔍 ", "") + "Tag Changes:")
 	origByTag := tagTotals(p.Original)
 	projByTag := tagTotals(p.Projected)
+	showDelta := p.ShowDelta
 
 	tagSet := map[string]bool{}
 	for tag := range origByTag {
@@ -363,18 +1841,42 @@ func printSideBySide(p Projection) {
 	for _, tag := range tags {
 		o, ok1 := origByTag[tag]
 		p, ok2 := projByTag[tag]
+		if !significantChange(o, p) {
+			continue
+		}
+		delta := ""
+		if showDelta {
+			delta = fmt.Sprintf("  (%s)", signedMoney(p-o))
+		}
 		if !ok1 {
-			fmt.Printf("  [%s] added:    %.2f\n", tag, p)
+			fmt.Printf("  [%s] added:    %s%s\n", tag, money(p), delta)
 		} else if !ok2 {
-			fmt.Printf("  [%s] removed:  %.2f\n", tag, o)
-		} else if o != p {
-			fmt.Printf("  [%s] changed:  %.2f → %.2f\n", tag, o, p)
+			fmt.Printf("  [%s] removed:  %s%s\n", tag, money(o), delta)
+		} else {
+			fmt.Printf("  [%s] changed:  %s → %s%s\n", tag, money(o), money(p), delta)
 		}
 	}
 
 	fmt.Println()
 }
 
+// significantChange reports whether a tag total moving from o to p is worth
+// listing: it must differ by at least a cent and meet any --min-change and
+// --min-change-pct thresholds. A missing total is passed as 0.
+func significantChange(o, p float64) bool {
+	delta := abs(p - o)
+	if delta < 0.005 {
+		return false
+	}
+	if minChange > 0 && delta < minChange {
+		return false
+	}
+	if minChangePct > 0 && o != 0 && delta/abs(o) < minChangePct {
+		return false
+	}
+	return true
+}
+
 func totalAmounts(transactions []Transaction) (income, expenses float64) {
 	for _, t := range transactions {
 		if t.Amount >= 0 {
@@ -389,19 +1891,35 @@ func totalAmounts(transactions []Transaction) (income, expenses float64) {
 func tagTotals(transactions []Transaction) map[string]float64 {
 	out := map[string]float64{}
 	for _, txn := range transactions {
-		if len(txn.Tags) == 0 {
-			out["_untagged_"] += txn.Amount
-		} else {
-			for _, tag := range txn.Tags {
-				out[tag] += txn.Amount
-			}
+		for tag, amount := range tagAmounts(txn) {
+			out[tag] += amount
 		}
 	}
 	return out
 }
 
+// exportProjectionMarkdown writes the projection report to filename. With
+// --export-append the report is added to the end of the file under a
+// timestamped heading, after a rule that also closes any trailing table.
 func exportProjectionMarkdown(p Projection, filename string) error {
-	f, err := os.Create(filename)
+	var f *os.File
+	var err error
+	var separator string
+	if exportAppend {
+		existing, readErr := os.ReadFile(filename)
+		if readErr != nil && !os.IsNotExist(readErr) {
+			return readErr
+		}
+		if len(existing) > 0 {
+			separator = "\n---\n\n"
+			if !strings.HasSuffix(string(existing), "\n") {
+				separator = "\n" + separator
+			}
+		}
+		f, err = os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	} else {
+		f, err = os.Create(filename)
+	}
 	if err != nil {
 		return err
 	}
@@ -411,56 +1929,71 @@ func exportProjectionMarkdown(p Projection, filename string) error {
 		fmt.Fprintf(f, format, args...)
 	}
 
-	w("# 📊 Cash Flow Projection\n\n")
+	if exportAppend {
+		w("%s# 📊 Cash Flow Projection (%s)\n\n", separator, time.Now().Format("2006-01-02 15:04"))
+	} else {
+		w("# 📊 Cash Flow Projection\n\n")
+	}
 	w("## Summary\n\n")
 	origIncome, origExpense := totalAmounts(p.Original)
 	projIncome, projExpense := totalAmounts(p.Projected)
 
 	w("| Metric   | Original | Projected |\n")
 	w("|----------|----------|-----------|\n")
-	w("| Income   | %.2f     | %.2f      |\n", origIncome, projIncome)
-	w("| Expenses | %.2f     | %.2f      |\n", -origExpense, -projExpense)
-	w("| Net      | %.2f     | %.2f      |\n\n", origIncome+origExpense, projIncome+projExpense)
+	w("| Income   | %s     | %s      |\n", money(origIncome), money(projIncome))
+	w("| Expenses | %s     | %s      |\n", money(-origExpense), money(-projExpense))
+	w("| Net      | %s     | %s      |\n\n", money(origIncome+origExpense), money(projIncome+projExpense))
+	origResidual, projResidual := roundingResidual(p.Original), roundingResidual(p.Projected)
+	if origResidual != 0 || projResidual != 0 {
+		w("Rounding residual (rounded rows minus rounded net): %+.0f original, %+.0f projected.\n\n", origResidual, projResidual)
+	}
 
-	w("## Tag Differences\n\n")
-	w("| Tag     | Original | Projected |\n")
-	w("|---------|----------|-----------|\n")
+	if !stripTags {
+		w("## Tag Differences\n\n")
+		w("| Tag     | Original | Projected |\n")
+		w("|---------|----------|-----------|\n")
 
-	origByTag := tagTotals(p.Original)
-	projByTag := tagTotals(p.Projected)
+		origByTag := tagTotals(p.Original)
+		projByTag := tagTotals(p.Projected)
 
-	tagSet := map[string]bool{}
-	for tag := range origByTag {
-		tagSet[tag] = true
-	}
-	for tag := range projByTag {
-		tagSet[tag] = true
-	}
+		tagSet := map[string]bool{}
+		for tag := range origByTag {
+			tagSet[tag] = true
+		}
+		for tag := range projByTag {
+			tagSet[tag] = true
+		}
 
-	tags := make([]string, 0, len(tagSet))
-	for tag := range tagSet {
-		tags = append(tags, tag)
-	}
-	sort.Strings(tags)
+		tags := make([]string, 0, len(tagSet))
+		for tag := range tagSet {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
 
-	for _, tag := range tags {
-		o, ok1 := origByTag[tag]
-		p, ok2 := projByTag[tag]
-		if !ok1 {
-			w("| %s | – | %.2f |\n", tag, p)
-		} else if !ok2 {
-			w("| %s | %.2f | – |\n", tag, o)
-		} else if o != p {
-			w("| %s | %.2f | %.2f |\n", tag, o, p)
+		for _, tag := range tags {
+			o, ok1 := origByTag[tag]
+			p, ok2 := projByTag[tag]
+			if !significantChange(o, p) {
+				continue
+			}
+			if !ok1 {
+				w("| %s | – | %s |\n", tag, money(p))
+			} else if !ok2 {
+				w("| %s | %s | – |\n", tag, money(o))
+			} else {
+				w("| %s | %s | %s |\n", tag, money(o), money(p))
+			}
 		}
+		w("\n")
 	}
 
-	w("\n## Transactions by Date\n\n")
+	w("## Transactions by Date\n\n")
 
 	// Group transactions by date
 	byDate := map[string][]struct {
 		Original  Transaction
 		Projected Transaction
+		Reason    string
 	}{}
 
 	for i := range p.Original {
@@ -468,9 +2001,11 @@ func exportProjectionMarkdown(p Projection, filename string) error {
 		byDate[dateStr] = append(byDate[dateStr], struct {
 			Original  Transaction
 			Projected Transaction
+			Reason    string
 		}{
 			Original:  p.Original[i],
 			Projected: p.Projected[i],
+			Reason:    p.Reasons[i],
 		})
 	}
 
@@ -481,30 +2016,89 @@ func exportProjectionMarkdown(p Projection, filename string) error {
 	}
 	sort.Strings(dates)
 
+	overridden := false
 	for _, date := range dates {
 		w("### %s\n\n", date)
-		w("| Description | Original | Projected | Tags |\n")
-		w("|-------------|----------|-----------|------|\n")
+		if stripTags {
+			w("| Description | Original | Projected |\n")
+			w("|-------------|----------|-----------|\n")
+		} else {
+			w("| Description | Original | Projected | Tags |\n")
+			w("|-------------|----------|-----------|------|\n")
+		}
 
 		for _, pair := range byDate[date] {
 			o := pair.Original
 			pj := pair.Projected
 
-			// Use projected amount if it differs
+			// Flag manual inline overrides so they stand out from tag rules
+			projected := money(abs(pj.Amount))
+			if strings.HasPrefix(pair.Reason, reasonInline) {
+				projected += " \\*"
+				overridden = true
+			}
+
+			if stripTags {
+				w("| %s | %s | %s |\n", o.Description, money(abs(o.Amount)), projected)
+				continue
+			}
 			tags := strings.Join(o.Tags, ", ")
-			w("| %s | %.2f | %.2f | %s |\n",
+			w("| %s | %s | %s | %s |\n",
 				o.Description,
-				abs(o.Amount),
-				abs(pj.Amount),
+				money(abs(o.Amount)),
+				projected,
 				tags,
 			)
 		}
 		w("\n")
 	}
 
+	if overridden {
+		w("\\* Projected amount entered manually with an inline `(…)` override; unmarked rows follow the tag adjustments.\n")
+	}
+
+	if tagTimelineReport && !stripTags {
+		w("\n## Tag Timeline\n\n")
+		w("| Tag | First Seen | Last Seen | Active Months | Total |\n")
+		w("|-----|------------|-----------|---------------|-------|\n")
+		for _, r := range tagTimeline(p.Original) {
+			w("| %s | %s | %s | %d | %s |\n", r.Tag, r.First.Format("2006-01-02"), r.Last.Format("2006-01-02"), r.ActiveMonths, money(r.Total))
+		}
+	}
+
 	return nil
 }
 
+// money formats an amount for display, to whole units with --round-whole.
+func money(v float64) string {
+	if roundWhole {
+		return fmt.Sprintf("%.0f", math.Round(v))
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+// signedMoney is money with an explicit sign, for deltas.
+func signedMoney(v float64) string {
+	if v < 0 {
+		return money(v)
+	}
+	return "+" + money(v)
+}
+
+// roundingResidual is how far the rounded line items are from the rounded
+// net when --round-whole is active; zero otherwise.
+func roundingResidual(transactions []Transaction) float64 {
+	if !roundWhole {
+		return 0
+	}
+	var net, sumRounded float64
+	for _, txn := range transactions {
+		net += txn.Amount
+		sumRounded += math.Round(txn.Amount)
+	}
+	return sumRounded - math.Round(net)
+}
+
 func abs(v float64) float64 {
 	if v < 0 {
 		return -v
@@ -0,0 +1,583 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// parseText parses an in-memory ledger, failing the test on error.
+func parseText(t *testing.T, text string) []Transaction {
+	t.Helper()
+	txns, _, err := parseLedger(strings.NewReader(text), "test.md")
+	if err != nil {
+		t.Fatalf("parseLedger: %v", err)
+	}
+	return txns
+}
+
+func TestParseCurrencyCode(t *testing.T) {
+	tests := []struct {
+		line        string
+		amount      float64
+		currency    string
+		description string
+	}{
+		{"- 50EUR", -50, "EUR", ""},
+		{"- 50 EUR Dinner [Dining]", -50, "EUR", "Dinner"},
+		{"- 50 RENT", -50, "", "RENT"},
+		{"- 40 ATM withdrawal [Cash]", -40, "", "ATM withdrawal"},
+		{"- 12 TAX", -12, "", "TAX"},
+	}
+	for _, tt := range tests {
+		txns := parseText(t, "# 2024-01-01\n"+tt.line+"\n")
+		if len(txns) != 1 {
+			t.Fatalf("%q: got %d transactions, want 1", tt.line, len(txns))
+		}
+		got := txns[0]
+		if got.Amount != tt.amount || got.Currency != tt.currency || got.Description != tt.description {
+			t.Errorf("%q: got %.2f %q %q, want %.2f %q %q", tt.line,
+				got.Amount, got.Currency, got.Description, tt.amount, tt.currency, tt.description)
+		}
+	}
+}
+
+func TestParseContradictedMarker(t *testing.T) {
+	defer func(v bool) { clampNegativeIncome = v }(clampNegativeIncome)
+	defer func(w []string) { warnings = w }(warnings)
+
+	tests := []struct {
+		line    string
+		clamp   bool
+		wantErr bool
+		amount  float64
+		tags    map[string]float64
+	}{
+		{"+ 100 Salary [Income] - 500 Fee [Bank]", false, true, 0, nil},
+		{"- 20 Return [Shop] + 50 Refund [Shop]", false, true, 0, nil},
+		{"+ 100 Salary [Income] - 500 Fee [Bank]", true, false, 400, map[string]float64{"Income": -100, "Bank": 500}},
+		{"+ 500 Salary [Income] - 100 Fee [Bank]", false, false, 400, map[string]float64{"Income": 500, "Bank": -100}},
+	}
+	for _, tt := range tests {
+		clampNegativeIncome = tt.clamp
+		warnings = nil
+		txns, _, err := parseLedger(strings.NewReader("# 2024-01-01\n"+tt.line+"\n"), "test.md")
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "contradicts") {
+				t.Errorf("%q: got error %v, want a contradiction", tt.line, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", tt.line, err)
+		}
+		if txns[0].Amount != tt.amount {
+			t.Errorf("%q: amount %.2f, want %.2f", tt.line, txns[0].Amount, tt.amount)
+		}
+		if got := tagAmounts(txns[0]); !reflect.DeepEqual(got, tt.tags) {
+			t.Errorf("%q: tag amounts %v, want %v", tt.line, got, tt.tags)
+		}
+		if tt.clamp && len(warnings) != 1 {
+			t.Errorf("%q: got warnings %q, want one flip warning", tt.line, warnings)
+		}
+	}
+}
+
+func TestParseContinuationLines(t *testing.T) {
+	defer func(v bool) { hashtags = v }(hashtags)
+	hashtags = true
+
+	tests := []struct {
+		name        string
+		text        string
+		count       int
+		description string
+		tags        []string
+	}{
+		{"two-line description", "- 40 Dinner with the\n  whole team [Work]\n", 1, "Dinner with the whole team", []string{"Work"}},
+		{"tags on the continuation", "- 40 Dinner with the\n  whole team [Work, Dining]\n", 1, "Dinner with the whole team", []string{"Work", "Dining"}},
+		{"hashtags on the continuation", "- 40 Dinner [Work]\n  with the team #dining\n", 1, "Dinner with the team", []string{"Work", "dining"}},
+		{"blank line ends the transaction", "- 40 Dinner [Work]\n\n  indented note\n", 1, "Dinner", []string{"Work"}},
+		{"marker starts a new line", "- 40 Dinner [Work]\n  - 5 Tip [Work]\n", 2, "Dinner", []string{"Work"}},
+		{"time starts a new line", "- 40 Dinner [Work]\n  14:30 - 5 Coffee [Food]\n", 2, "Dinner", []string{"Work"}},
+		{"components take the tags", "- 40 Dinner [Work] - 5 Tip [Tips]\n  with the team [Team]\n", 1, "Dinner with the team", []string{"Work", "Tips", "Team"}},
+	}
+	for _, tt := range tests {
+		txns := parseText(t, "# 2024-01-01\n"+tt.text)
+		if len(txns) != tt.count {
+			t.Fatalf("%s: got %d transactions, want %d", tt.name, len(txns), tt.count)
+		}
+		got := txns[0]
+		if got.Description != tt.description || !reflect.DeepEqual(got.Tags, tt.tags) {
+			t.Errorf("%s: got %q %q, want %q %q", tt.name, got.Description, got.Tags, tt.description, tt.tags)
+		}
+		if len(got.Components) > 0 && !hasTag(Transaction{Tags: got.Components[0].Tags}, "Team") {
+			t.Errorf("%s: first component tags %q lack the continuation's tag", tt.name, got.Components[0].Tags)
+		}
+	}
+}
+
+func TestParseBalanceAssertion(t *testing.T) {
+	defer func(v float64) { startingBalance = v }(startingBalance)
+	startingBalance = 100
+
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{"from --starting-balance", "# 2024-01-01\n- 30 Lunch [Food]\n= 70\n", ""},
+		{"from frontmatter", "---\naccount: Savings\nstarting_balance: 1000\n---\n# 2024-01-01\n- 30 Lunch [Food]\n= 970\n", ""},
+		{"frontmatter replaces the flag", "---\nstarting_balance: 1000\n---\n# 2024-01-01\n= 100\n", "expected 100.00, running balance is 1000.00"},
+		{"mismatch", "# 2024-01-01\n- 30 Lunch [Food]\n= 80\n", "test.md:3: balance assertion failed"},
+		{"invalid frontmatter", "---\nstarting_balance: lots\n---\n", "invalid starting_balance"},
+	}
+	for _, tt := range tests {
+		_, _, err := parseLedger(strings.NewReader(tt.text), "test.md")
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseWarnings(t *testing.T) {
+	defer func(w []string) { warnings = w }(warnings)
+	defer func(v bool) { tolerant = v }(tolerant)
+	tolerant = true
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"- 9.499 Coffee [Food]", "test.md:2: amount 9.499 has more than 2 decimal places"},
+		{"- 5 Lunch [Food", "test.md:2: recovered malformed line"},
+		{"- 5 Lunch [Food, food]", `test.md:2: duplicate tag "food"`},
+		{"Lunch 5", `test.md:2: unparsed line: "Lunch 5"`},
+	}
+	for _, tt := range tests {
+		warnings = nil
+		parseText(t, "# 2024-01-01\n"+tt.line+"\n")
+		var b strings.Builder
+		printWarnings(&b)
+		if len(warnings) != 1 || !strings.HasPrefix(warnings[0], tt.want) {
+			t.Errorf("%q: warnings %q, want one starting %q", tt.line, warnings, tt.want)
+		}
+		if !strings.Contains(b.String(), "1 warning(s):\n  "+tt.want) {
+			t.Errorf("%q: printWarnings wrote %q", tt.line, b.String())
+		}
+	}
+}
+
+func TestRepairLine(t *testing.T) {
+	defer func(v bool) { tolerant = v }(tolerant)
+	defer func(v bool) { strict = v }(strict)
+	tolerant, strict = true, true
+
+	tests := []struct {
+		line    string
+		fixed   string
+		assumed string
+	}{
+		{"- 5 Smile :) [Fun]", "- 5 Smile :) [Fun]", ""},
+		{"- 5 Lunch [Food] 4)", "- 5 Lunch [Food]", "unbalanced ) so the projection was ignored"},
+		{"- 5 Lunch [Food] (4", "- 5 Lunch [Food]", "unbalanced ( so the projection was ignored"},
+		{"- 5 Lunch [Food", "- 5 Lunch [Food]", "unclosed [ so tags were taken to the end of the line"},
+	}
+	for _, tt := range tests {
+		fixed, assumed := repairLine(tt.line)
+		if fixed != tt.fixed || assumed != tt.assumed {
+			t.Errorf("repairLine(%q) = %q, %q; want %q, %q", tt.line, fixed, assumed, tt.fixed, tt.assumed)
+		}
+		_, _, err := parseLedger(strings.NewReader("# 2024-01-01\n"+tt.line+"\n"), "test.md")
+		if (err != nil) != (tt.assumed != "") {
+			t.Errorf("%q under --strict: error %v", tt.line, err)
+		}
+	}
+}
+
+func TestPrimaryTagOnlyTotals(t *testing.T) {
+	defer func(v bool) { primaryTagOnly = v }(primaryTagOnly)
+	primaryTagOnly = true
+
+	tests := []struct {
+		name   string
+		ledger string
+	}{
+		{"multi-tag lines", "- 60 Coffee [Food, Client Meeting]\n- 20 Taxi [Commute, Work, Client Meeting]\n+ 300 Freelance [Contract X, Work]\n"},
+		{"untagged lines", "- 60 Coffee [Food, Work]\n- 20 Cash\n"},
+		{"components", "- 120 Groceries [Food, Home] - 30 Soap [Home, Cleaning]\n+ 10 Refund [Food]\n"},
+	}
+	for _, tt := range tests {
+		txns := parseText(t, "# 2024-01-01\n"+tt.ledger)
+		var grand, tagged float64
+		for _, txn := range txns {
+			grand += txn.Amount
+		}
+		for _, total := range tagTotals(txns) {
+			tagged += total
+		}
+		if math.Abs(grand-tagged) > 1e-9 {
+			t.Errorf("%s: per-tag totals sum to %.2f, grand total is %.2f", tt.name, tagged, grand)
+		}
+	}
+}
+
+func TestParseAmountOnlyLines(t *testing.T) {
+	tests := []struct {
+		line        string
+		amount      float64
+		description string
+		tags        []string
+		projected   float64 // 0 means none
+	}{
+		{"- 20", -20, "", []string{}, 0},
+		{"- 20 [Cash]", -20, "", []string{"Cash"}, 0},
+		{"+ 15.5 [Gift, Family]", 15.5, "", []string{"Gift", "Family"}, 0},
+		{"- 20 [Cash] (25)", -20, "", []string{"Cash"}, 25},
+		{"- 20 ATM [Cash]", -20, "ATM", []string{"Cash"}, 0},
+	}
+	for _, tt := range tests {
+		txns := parseText(t, "# 2024-01-01\n"+tt.line+"\n")
+		if len(txns) != 1 {
+			t.Fatalf("%q: got %d transactions, want 1", tt.line, len(txns))
+		}
+		got := txns[0]
+		if got.Amount != tt.amount || got.Description != tt.description || !reflect.DeepEqual(got.Tags, tt.tags) {
+			t.Errorf("%q: got %.2f %q %q, want %.2f %q %q", tt.line, got.Amount, got.Description, got.Tags, tt.amount, tt.description, tt.tags)
+		}
+		if (got.ProjectedAmount != nil) != (tt.projected != 0) || (got.ProjectedAmount != nil && *got.ProjectedAmount != tt.projected) {
+			t.Errorf("%q: projected %v, want %.2f", tt.line, got.ProjectedAmount, tt.projected)
+		}
+	}
+}
+
+func TestParseSectionTags(t *testing.T) {
+	defer func(v bool) { sectionTags = v }(sectionTags)
+	sectionTags = true
+
+	txns := parseText(t, `# 2024-01-01
+- 1 Before any heading
+## Food
+- 2 Bread
+- 3 Dinner [Dining]
+### Coffee
+- 4 Latte
+## Transport
+- 5 Bus
+# 2024-01-02
+- 6 After a new date
+`)
+	want := []struct {
+		description string
+		tags        []string
+	}{
+		{"Before any heading", []string{}},
+		{"Bread", []string{"Food"}},
+		{"Dinner", []string{"Dining"}},
+		{"Latte", []string{"Coffee"}},
+		{"Bus", []string{"Transport"}},
+		{"After a new date", []string{}},
+	}
+	if len(txns) != len(want) {
+		t.Fatalf("got %d transactions, want %d", len(txns), len(want))
+	}
+	for i, w := range want {
+		if txns[i].Description != w.description || !reflect.DeepEqual(txns[i].Tags, w.tags) {
+			t.Errorf("line %d: got %q %q, want %q %q", i, txns[i].Description, txns[i].Tags, w.description, w.tags)
+		}
+	}
+}
+
+func TestProjectionInflation(t *testing.T) {
+	defer func(v float64, d string) { inflation, inflationBaseDate = v, d }(inflation, inflationBaseDate)
+	inflation, inflationBaseDate = 0.03, "2020-01-01"
+
+	txns := parseText(t, `# 2020-01-01
+- 100 Rent [Housing]
+# 2022-01-01
+- 100 Rent [Housing]
+- 100 Groceries [Food]
+# 2025-01-01
+- 100 Rent [Housing]
+# 2019-01-01
+- 100 Rent [Housing]
+`)
+	want := []float64{-100, -106.09, -116.70, -115.93, -97.09}
+	p := buildProjection(txns, "Food=0.1")
+	for i, w := range want {
+		if got := p.Projected[i].Amount; math.Abs(got-w) > 0.005 {
+			t.Errorf("%s %s: projected %.4f, want %.2f", p.Projected[i].Date.Format("2006-01-02"), p.Projected[i].Description, got, w)
+		}
+	}
+}
+
+func TestProjectionInterest(t *testing.T) {
+	defer func(s string, m, f int) { interest, interestMonths, forecastMonths = s, m, f }(interest, interestMonths, forecastMonths)
+	interest = "Savings=0.04,Loan=0.19"
+
+	txns := parseText(t, `# 2024-01-01
++ 100 Deposit [Savings]
+- 1000 Balance [Loan]
+- 50 Groceries [Food]
+`)
+	tests := []struct {
+		name     string
+		months   int
+		forecast int
+		adjust   string
+		want     []float64
+	}{
+		{"one year", 0, 12, "", []float64{104.07, -1207.45, -50}},
+		{"after an adjustment", 0, 12, "Savings=0.1", []float64{114.48, -1207.45, -50}},
+		{"own horizon without a forecast", 12, 0, "", []float64{104.07, -1207.45, -50}},
+		{"own horizon over the forecast", 12, 3, "", []float64{104.07, -1207.45, -50}},
+		{"no horizon", 0, 0, "", []float64{100, -1000, -50}},
+	}
+	for _, tt := range tests {
+		interestMonths, forecastMonths = tt.months, tt.forecast
+		p := buildProjection(txns, tt.adjust)
+		for i, w := range tt.want {
+			if got := p.Projected[i].Amount; math.Abs(got-w) > 0.005 {
+				t.Errorf("%s: %s projected %.4f, want %.2f", tt.name, txns[i].Description, got, w)
+			}
+		}
+	}
+}
+
+func TestIntradayOrdering(t *testing.T) {
+	txns := parseText(t, `# 2024-02-01
+18:00 - 80 Dinner [Food]
+- 5 Undated snack [Food]
+09:15 + 100 Refund [Income]
+# 2024-02-01 14:30
+- 20 Afternoon tea [Food]
+# 2024-01-31
+23:59 - 1 Late fee [Bank]
+`)
+	sortByDate(txns)
+	want := []string{"Late fee", "Undated snack", "Refund", "Afternoon tea", "Dinner"}
+	var got []string
+	for _, txn := range txns {
+		got = append(got, txn.Description)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("order %q, want %q", got, want)
+	}
+
+	// The low point depends on the order within the day.
+	balance, low := 0.0, 0.0
+	for _, txn := range txns {
+		balance += txn.Amount
+		low = min(low, balance)
+	}
+	if low != -6 {
+		t.Errorf("lowest running balance %.2f, want -6.00", low)
+	}
+	if got := dateLabel(txns[3].Date); got != "2024-02-01 14:30" {
+		t.Errorf("header time labelled %q", got)
+	}
+}
+
+func TestProjectionFloor(t *testing.T) {
+	defer func(f bool, s string) { projectionFloor, allowSignFlip = f, s }(projectionFloor, allowSignFlip)
+
+	txns := parseText(t, "# 2024-01-01\n+ 1000 Salary [Salary]\n- 200 Food [Food]\n+ 50 Cashback [Rewards]\n")
+	tests := []struct {
+		name    string
+		floor   bool
+		allow   string
+		adjust  string
+		want    []float64
+		floored int
+	}{
+		{"sign flips without a floor", false, "", "Salary=-2.0,Food=-1.5", []float64{-1000, 100, 50}, 0},
+		{"floor clamps at zero", true, "", "Salary=-2.0,Food=-1.5", []float64{0, 0, 50}, 2},
+		{"exactly -100%", true, "", "Salary=-1.0", []float64{0, -200, 50}, 0},
+		{"expense at exactly -100%", true, "", "Food=-1.0", []float64{1000, 0, 50}, 0},
+		{"allowed flip", true, "Salary", "Salary=-2.0,Food=-1.5", []float64{-1000, 0, 50}, 1},
+	}
+	for _, tt := range tests {
+		projectionFloor, allowSignFlip = tt.floor, tt.allow
+		p := buildProjection(txns, tt.adjust)
+		for i, w := range tt.want {
+			if got := p.Projected[i].Amount; math.Abs(got-w) > 1e-9 {
+				t.Errorf("%s: %s projected %.2f, want %.2f", tt.name, txns[i].Description, got, w)
+			}
+		}
+		if p.Floored != tt.floored {
+			t.Errorf("%s: floored %d, want %d", tt.name, p.Floored, tt.floored)
+		}
+		for i, reason := range p.Reasons {
+			if reason != strings.TrimSpace(reason) {
+				t.Errorf("%s: %s reason %q has stray spaces", tt.name, txns[i].Description, reason)
+			}
+		}
+	}
+}
+
+func TestProjectionCap(t *testing.T) {
+	defer func(c, pct float64) { projectionCap, projectionCapPct = c, pct }(projectionCap, projectionCapPct)
+
+	txns := parseText(t, "# 2024-01-01\n+ 1000 Salary [Salary]\n- 200 Food [Food]\n")
+	tests := []struct {
+		name    string
+		cap     float64
+		pct     float64
+		adjust  string
+		want    []float64
+		reasons []string
+	}{
+		{"cap without adjustments", 500, 0, "", []float64{500, -200}, []string{"(capped)", ""}},
+		{"percentage cap", 0, 0.5, "Food=1.0", []float64{1000, -300}, []string{"", "Food=+1 (capped)"}},
+	}
+	for _, tt := range tests {
+		projectionCap, projectionCapPct = tt.cap, tt.pct
+		p := buildProjection(txns, tt.adjust)
+		for i, w := range tt.want {
+			if got := p.Projected[i].Amount; math.Abs(got-w) > 1e-9 {
+				t.Errorf("%s: %s projected %.2f, want %.2f", tt.name, txns[i].Description, got, w)
+			}
+		}
+		if !reflect.DeepEqual(p.Reasons, tt.reasons) {
+			t.Errorf("%s: reasons %q, want %q", tt.name, p.Reasons, tt.reasons)
+		}
+	}
+}
+
+func TestExportProjectionRoundWhole(t *testing.T) {
+	defer func(v bool) { roundWhole = v }(roundWhole)
+	roundWhole = true
+
+	txns := parseText(t, "# 2024-01-01\n+ 1000.40 Salary [Salary]\n- 200.60 Food [Food]\n")
+	filename := filepath.Join(t.TempDir(), "projection.md")
+	if err := exportProjectionMarkdown(buildProjection(txns, "Food=0.1"), filename); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "| Food | -201 | -221 |"; !strings.Contains(string(out), want) {
+		t.Errorf("tag differences lack %q:\n%s", want, out)
+	}
+	if strings.Contains(string(out), ".60") {
+		t.Errorf("--round-whole export shows cents:\n%s", out)
+	}
+}
+
+func TestUntaggedAs(t *testing.T) {
+	defer func(v string) { untaggedAs = v }(untaggedAs)
+	untaggedAs = "Misc"
+
+	txns := applyUntaggedAs(parseText(t, "# 2024-01-01\n- 20 Cash\n- 5 Gum\n- 40 Dinner [Food]\n+ 10 Found\n"))
+	buckets := map[string]map[string]float64{
+		"tag totals":  tagTotals(txns),
+		"tag stats":   {},
+		"high impact": {},
+	}
+	for tag, st := range tagStats(txns) {
+		buckets["tag stats"][tag] = st.Total
+	}
+	for _, ti := range expenseImpact(txns) {
+		buckets["high impact"][ti.Tag] = ti.Total
+	}
+	for name, totals := range buckets {
+		if _, ok := totals["_untagged_"]; ok {
+			t.Errorf("%s has an _untagged_ bucket: %v", name, totals)
+		}
+		if _, ok := totals["Misc"]; !ok {
+			t.Errorf("%s lacks the Misc bucket: %v", name, totals)
+		}
+	}
+	if got := tagTotals(txns)["Misc"]; got != -15 {
+		t.Errorf("Misc totals %.2f, want -15.00", got)
+	}
+	for _, txn := range txns {
+		if len(txn.Tags) == 0 || (txn.Description != "Dinner" && !hasTag(txn, "misc")) {
+			t.Errorf("%q tagged %q, want Misc", txn.Description, txn.Tags)
+		}
+	}
+}
+
+func TestSortByDate(t *testing.T) {
+	tests := []struct {
+		name   string
+		ledger string
+		want   []string
+	}{
+		{"already sorted", "# 2024-01-01\n- 1 A\n# 2024-01-02\n- 2 B\n", []string{"A", "B"}},
+		{"reversed days", "# 2024-01-03\n- 1 C\n# 2024-01-02\n- 2 B\n# 2024-01-01\n- 3 A\n", []string{"A", "B", "C"}},
+		{"same day keeps file order", "# 2024-01-02\n- 1 B1\n- 2 B2\n# 2024-01-01\n- 3 A\n# 2024-01-02\n- 4 B3\n", []string{"A", "B1", "B2", "B3"}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		txns := parseText(t, tt.ledger)
+		sortByDate(txns)
+		var got []string
+		for _, txn := range txns {
+			got = append(got, txn.Description)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: order %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHasAllTags(t *testing.T) {
+	txn := Transaction{Tags: []string{"Work", "Travel", "Food"}}
+	tests := []struct {
+		tags []string
+		want bool
+	}{
+		{nil, true},
+		{[]string{"Work"}, true},
+		{[]string{"work", "TRAVEL"}, true},
+		{[]string{"Work", "Travel", "Food"}, true},
+		{[]string{"Work", "Home"}, false},
+		{[]string{"Home"}, false},
+	}
+	for _, tt := range tests {
+		if got := hasAllTags(txn, tt.tags); got != tt.want {
+			t.Errorf("hasAllTags(%q) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestApplyFiltersTagAll(t *testing.T) {
+	defer func(a, b string) { filterTag, filterTagAll = a, b }(filterTag, filterTagAll)
+
+	txns := parseText(t, `# 2024-01-01
+- 300 Flight [Work, Travel]
+- 40 Taxi [work, travel, Client]
+- 200 Holiday [Travel]
+- 60 Dinner [Work, Food]
+- 20 Team lunch [Work, Food, Client]
+`)
+	tests := []struct {
+		tag, tagAll string
+		want        []string
+	}{
+		{"", "Work,Travel", []string{"Flight", "Taxi"}},
+		{"", "TRAVEL", []string{"Flight", "Taxi", "Holiday"}},
+		{"Client", "Work,Travel", []string{"Taxi"}},
+		{"Food,Travel", "Work", []string{"Flight", "Taxi", "Dinner", "Team lunch"}},
+		{"Food", "Client", []string{"Team lunch"}},
+		{"", "Work,Home", nil},
+	}
+	for _, tt := range tests {
+		filterTag, filterTagAll = tt.tag, tt.tagAll
+		var got []string
+		for _, txn := range applyFilters(txns) {
+			got = append(got, txn.Description)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("--tag %q --tag-all %q kept %q, want %q", tt.tag, tt.tagAll, got, tt.want)
+		}
+	}
+}
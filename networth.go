@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Account is one ledger file's contribution to a net-worth snapshot.
+type Account struct {
+	Name            string
+	StartingBalance float64
+	Change          float64
+}
+
+func (a Account) Balance() float64 {
+	return a.StartingBalance + a.Change
+}
+
+// loadAccount parses a ledger file and sums its transactions up to and
+// including asOf (all of them when asOf is zero). The account name and
+// starting balance come from the file's frontmatter.
+func loadAccount(filename string, asOf time.Time) (Account, error) {
+	meta, err := readFrontmatter(filename)
+	if err != nil {
+		return Account{}, err
+	}
+
+	acct := Account{Name: meta["account"]}
+	if acct.Name == "" {
+		acct.Name = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+	if v, ok := meta["starting_balance"]; ok {
+		acct.StartingBalance, err = strconv.ParseFloat(v, 64)
+		if err != nil {
+			return Account{}, fmt.Errorf("%s: invalid starting_balance %q", filename, v)
+		}
+	}
+
+	transactions, _, err := parseFile(filename)
+	if err != nil {
+		return Account{}, err
+	}
+	for _, txn := range transactions {
+		if !asOf.IsZero() && dayOf(txn.Date).After(asOf) {
+			continue
+		}
+		acct.Change += txn.Amount
+	}
+	return acct, nil
+}
+
+func printNetWorth(files []string, asOf time.Time) {
+	title := "Net Worth"
+	if !asOf.IsZero() {
+		title += " as of " + asOf.Format("2006-01-02")
+	}
+	fmt.Println(glyph("💰 ", "") + title + ":")
+
+	var total float64
+	for _, filename := range files {
+		acct, err := loadAccount(filename, asOf)
+		if err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		fmt.Printf("  %-20s %10.2f\n", acct.Name, acct.Balance())
+		total += acct.Balance()
+	}
+
+	fmt.Printf("  %-20s %10.2f\n\n", "Total", total)
+}
+
+// carryForward sets --starting-balance to the closing balance of a prior
+// ledger, so consecutive period files chain together. A missing prior file
+// leaves the starting balance as it was. The prior file's warnings are
+// reported on their own, and its balance assertions count from its own
+// frontmatter, so neither leaks into the current run. Notices go to stderr
+// to keep stdout for the report.
+func carryForward(priorFile string) {
+	if _, err := os.Stat(priorFile); errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Prior file %s not found; starting from %.2f\n\n", priorFile, startingBalance)
+		return
+	}
+
+	saved, savedUntagged := warnings, untaggedCount
+	warnings, untaggedCount = nil, 0
+	startingBalance = 0
+	acct, err := loadAccount(priorFile, time.Time{})
+	priorWarnings := warnings
+	warnings, untaggedCount = saved, savedUntagged
+	if err != nil {
+		fmt.Println("Error reading prior file:", err)
+		os.Exit(1)
+	}
+	startingBalance = acct.Balance()
+
+	if len(priorWarnings) > 0 {
+		fmt.Fprintf(os.Stderr, "%s%d warning(s) in prior file %s:\n", glyph("⚠️  ", ""), len(priorWarnings), priorFile)
+		for _, warning := range priorWarnings {
+			fmt.Fprintln(os.Stderr, "  "+warning)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+	fmt.Fprintf(os.Stderr, "%sCarried forward %.2f from %s\n\n", glyph("↪️  ", ""), startingBalance, priorFile)
+}
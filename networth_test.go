@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCarryForward(t *testing.T) {
+	defer func(v float64) { startingBalance = v }(startingBalance)
+	defer func(w []string, n int) { warnings, untaggedCount = w, n }(warnings, untaggedCount)
+	defer func(v bool) { requireTags = v }(requireTags)
+	requireTags = true
+
+	dir := t.TempDir()
+	tests := []struct {
+		name  string
+		prior string // "" means the prior file does not exist
+		start float64
+		want  float64
+	}{
+		{"missing prior", "", 50, 50},
+		{"closing balance", "# 2024-01-01\n+ 100 Pay [Income]\n- 30 Food [Food]\n", 50, 70},
+		{"frontmatter balance", "---\nstarting_balance: 1000\n---\n# 2024-01-01\n- 30 Food [Food]\n= 970\n", 50, 970},
+		{"assertion ignores the current start", "# 2024-01-01\n- 30 Food [Food]\n= -30\n", 50, -30},
+		{"warnings stay with the prior", "# 2024-01-01\n- 30 Food\n- 9.499 Coffee [Food]\n", 50, -39.499},
+	}
+	for i, tt := range tests {
+		filename := filepath.Join(dir, "prior"+string(rune('a'+i))+".md")
+		if tt.prior != "" {
+			if err := os.WriteFile(filename, []byte(tt.prior), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		startingBalance = tt.start
+		warnings, untaggedCount = []string{"current"}, 0
+		carryForward(filename)
+		if startingBalance != tt.want {
+			t.Errorf("%s: starting balance %.3f, want %.3f", tt.name, startingBalance, tt.want)
+		}
+		if len(warnings) != 1 || warnings[0] != "current" || untaggedCount != 0 {
+			t.Errorf("%s: current warnings became %q (untagged %d)", tt.name, warnings, untaggedCount)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// exportOFX writes transactions as an OFX 2.2 bank statement that
+// accounting software can import. Amounts keep their sign (negative for
+// money leaving the account), which is also OFX's convention.
+func exportOFX(transactions []Transaction, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := func(format string, args ...interface{}) {
+		fmt.Fprintf(f, format, args...)
+	}
+
+	now := time.Now().Format("20060102150405")
+	var start, end time.Time
+	var balance float64
+	for i, txn := range transactions {
+		if i == 0 || txn.Date.Before(start) {
+			start = txn.Date
+		}
+		if i == 0 || txn.Date.After(end) {
+			end = txn.Date
+		}
+		balance += txn.Amount
+	}
+
+	w("<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"no\"?>\n")
+	w("<?OFX OFXHEADER=\"200\" VERSION=\"220\" SECURITY=\"NONE\" OLDFILEUID=\"NONE\" NEWFILEUID=\"NONE\"?>\n")
+	w("<OFX>\n")
+	w("  <SIGNONMSGSRSV1><SONRS>\n")
+	w("    <STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	w("    <DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE>\n", now)
+	w("  </SONRS></SIGNONMSGSRSV1>\n")
+	w("  <BANKMSGSRSV1><STMTTRNRS>\n")
+	w("    <TRNUID>1</TRNUID>\n")
+	w("    <STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	w("    <STMTRS>\n")
+	w("      <CURDEF>%s</CURDEF>\n", strings.ToUpper(baseCurrency))
+	w("      <BANKACCTFROM><BANKID>0</BANKID><ACCTID>cashflow</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>\n")
+	w("      <BANKTRANLIST>\n")
+	w("        <DTSTART>%s</DTSTART><DTEND>%s</DTEND>\n", start.Format("20060102"), end.Format("20060102"))
+
+	for i, txn := range transactions {
+		trnType := "CREDIT"
+		if txn.Amount < 0 {
+			trnType = "DEBIT"
+		}
+		name := txn.Description
+		if name == "" {
+			name = "Unspecified"
+		}
+		if r := []rune(name); len(r) > 32 {
+			name = string(r[:32])
+		}
+		memo := txn.Description
+		if len(txn.Tags) > 0 {
+			memo = strings.TrimSpace(memo + " [" + strings.Join(txn.Tags, ", ") + "]")
+		}
+
+		w("        <STMTTRN>\n")
+		w("          <TRNTYPE>%s</TRNTYPE>\n", trnType)
+		w("          <DTPOSTED>%s</DTPOSTED>\n", txn.Date.Format("20060102"))
+		w("          <TRNAMT>%.2f</TRNAMT>\n", txn.Amount)
+		w("          <FITID>%s-%d</FITID>\n", txn.Date.Format("20060102"), i+1)
+		w("          <NAME>%s</NAME>\n", xmlEscape(name))
+		if memo != "" {
+			w("          <MEMO>%s</MEMO>\n", xmlEscape(memo))
+		}
+		w("        </STMTTRN>\n")
+	}
+
+	w("      </BANKTRANLIST>\n")
+	w("      <LEDGERBAL><BALAMT>%.2f</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>\n", balance, end.Format("20060102"))
+	w("    </STMTRS>\n")
+	w("  </STMTTRNRS></BANKMSGSRSV1>\n")
+	w("</OFX>\n")
+
+	return nil
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// tagFilename turns a tag into a safe file name, e.g. "Side Hustle" →
+// "Side_Hustle.md" and "a/b" → "a_b.md".
+func tagFilename(tag string) string {
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(tag, "_"), "_.")
+	if name == "" {
+		name = "tag"
+	}
+	return name + ".md"
+}
+
+// exportPerTag writes one projection export per tag into dir, each covering
+// only the transactions carrying that tag. It returns the number of files
+// written.
+func exportPerTag(transactions []Transaction, adjust, dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+
+	byTag := map[string][]Transaction{}
+	for _, txn := range transactions {
+		if len(txn.Tags) == 0 {
+			byTag["_untagged_"] = append(byTag["_untagged_"], txn)
+		}
+		for _, tag := range txn.Tags {
+			byTag[tag] = append(byTag[tag], txn)
+		}
+	}
+
+	tags := make([]string, 0, len(byTag))
+	for tag := range byTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	used := map[string]bool{}
+	written := 0
+	for _, tag := range tags {
+		name := tagFilename(tag)
+		for i := 2; used[strings.ToLower(name)]; i++ {
+			name = fmt.Sprintf("%s-%d.md", strings.TrimSuffix(tagFilename(tag), ".md"), i)
+		}
+		used[strings.ToLower(name)] = true
+
+		projection := buildProjection(byTag[tag], adjust)
+		if err := exportProjectionMarkdown(projection, filepath.Join(dir, name)); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
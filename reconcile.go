@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reconciliation is the result of matching ledger transactions against a
+// bank statement.
+type Reconciliation struct {
+	Matched    int
+	Mismatched [][2]Transaction // ledger, statement: same day and description, different amount
+	LedgerOnly []Transaction
+	StmtOnly   []Transaction
+}
+
+// reconcile pairs each ledger transaction with a statement transaction on
+// the same date whose amount is within tolerance. Leftovers on the same date
+// with the same description are reported as amount mismatches; everything
+// else is unmatched.
+func reconcile(ledger, statement []Transaction, tolerance float64) Reconciliation {
+	var r Reconciliation
+	used := make([]bool, len(statement))
+
+	var pending []Transaction
+	for _, l := range ledger {
+		found := false
+		for j, s := range statement {
+			if !used[j] && sameDay(l.Date, s.Date) && abs(l.Amount-s.Amount) <= tolerance+1e-9 {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if found {
+			r.Matched++
+		} else {
+			pending = append(pending, l)
+		}
+	}
+
+	for _, l := range pending {
+		found := false
+		for j, s := range statement {
+			if !used[j] && sameDay(l.Date, s.Date) && strings.EqualFold(strings.TrimSpace(l.Description), strings.TrimSpace(s.Description)) {
+				used[j] = true
+				found = true
+				r.Mismatched = append(r.Mismatched, [2]Transaction{l, s})
+				break
+			}
+		}
+		if !found {
+			r.LedgerOnly = append(r.LedgerOnly, l)
+		}
+	}
+
+	for j, s := range statement {
+		if !used[j] {
+			r.StmtOnly = append(r.StmtOnly, s)
+		}
+	}
+	return r
+}
+
+func printReconciliation(r Reconciliation) {
+	fmt.Println(glyph("🧾 ", "") + "Reconciliation:")
+	fmt.Printf("  Matched:        %d\n", r.Matched)
+	fmt.Printf("  Mismatched:     %d\n", len(r.Mismatched))
+	fmt.Printf("  Ledger only:    %d\n", len(r.LedgerOnly))
+	fmt.Printf("  Statement only: %d\n", len(r.StmtOnly))
+
+	if len(r.Mismatched) > 0 {
+		fmt.Println("\n  Amount mismatches (ledger → statement):")
+		for _, pair := range r.Mismatched {
+			fmt.Printf("    %s %s: %.2f → %.2f\n", pair[0].Date.Format("2006-01-02"), pair[0].Description, pair[0].Amount, pair[1].Amount)
+		}
+	}
+	if len(r.LedgerOnly) > 0 {
+		fmt.Println("\n  Only in ledger:")
+		for _, t := range r.LedgerOnly {
+			fmt.Printf("    %s %.2f %s\n", t.Date.Format("2006-01-02"), t.Amount, t.Description)
+		}
+	}
+	if len(r.StmtOnly) > 0 {
+		fmt.Println("\n  Only in statement:")
+		for _, t := range r.StmtOnly {
+			fmt.Printf("    %s %.2f %s\n", t.Date.Format("2006-01-02"), t.Amount, t.Description)
+		}
+	}
+	fmt.Println()
+}
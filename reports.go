@@ -0,0 +1,880 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TagPair is an unordered pair of tags seen on the same transaction.
+type TagPair struct {
+	A, B   string
+	Count  int
+	Amount float64
+}
+
+func tagCooccurrence(transactions []Transaction) []TagPair {
+	pairs := map[[2]string]*TagPair{}
+	for _, txn := range transactions {
+		// A tag repeated in another case is still one tag, not a pair.
+		var tags []string
+		for _, tag := range txn.Tags {
+			if !hasTag(Transaction{Tags: tags}, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		for i := 0; i < len(tags); i++ {
+			for j := i + 1; j < len(tags); j++ {
+				a, b := tags[i], tags[j]
+				if b < a {
+					a, b = b, a
+				}
+				key := [2]string{a, b}
+				if pairs[key] == nil {
+					pairs[key] = &TagPair{A: a, B: b}
+				}
+				pairs[key].Count++
+				pairs[key].Amount += txn.Amount
+			}
+		}
+	}
+
+	out := make([]TagPair, 0, len(pairs))
+	for _, p := range pairs {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		if abs(out[i].Amount) != abs(out[j].Amount) {
+			return abs(out[i].Amount) > abs(out[j].Amount)
+		}
+		return out[i].A+out[i].B < out[j].A+out[j].B
+	})
+	return out
+}
+
+func printCooccurrence(transactions []Transaction) {
+	fmt.Println(glyph("🔗 ", "") + "Tag Co-occurrence:")
+	pairs := tagCooccurrence(transactions)
+	if len(pairs) == 0 {
+		fmt.Println("  (no transactions with two or more tags)")
+	}
+	for i, p := range pairs {
+		if topN > 0 && i >= topN {
+			break
+		}
+		fmt.Printf("  [%s] + [%s]: %d× %.2f\n", p.A, p.B, p.Count, p.Amount)
+	}
+	fmt.Println()
+}
+
+// Bucket counts the expenses whose magnitude falls in [Low, High).
+// High is zero for the open-ended last bucket.
+type Bucket struct {
+	Low, High float64
+	Count     int
+	Total     float64
+}
+
+func parseBuckets(s string) ([]float64, error) {
+	var bounds []float64
+	for _, item := range splitList(s) {
+		v, err := strconv.ParseFloat(item, 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid bucket boundary %q", item)
+		}
+		if len(bounds) > 0 && v <= bounds[len(bounds)-1] {
+			return nil, fmt.Errorf("bucket boundaries must be increasing")
+		}
+		bounds = append(bounds, v)
+	}
+	return bounds, nil
+}
+
+func expenseHistogram(transactions []Transaction, bounds []float64) []Bucket {
+	buckets := make([]Bucket, len(bounds)+1)
+	low := 0.0
+	for i, b := range bounds {
+		buckets[i] = Bucket{Low: low, High: b}
+		low = b
+	}
+	buckets[len(bounds)] = Bucket{Low: low}
+
+	for _, txn := range transactions {
+		if txn.Amount >= 0 {
+			continue
+		}
+		amt := -txn.Amount
+		i := sort.SearchFloat64s(bounds, amt)
+		if i < len(bounds) && bounds[i] == amt {
+			i++
+		}
+		buckets[i].Count++
+		buckets[i].Total += amt
+	}
+	return buckets
+}
+
+func printHistogram(transactions []Transaction, bounds []float64) {
+	fmt.Println(glyph("📶 ", "") + "Expense Histogram:")
+	buckets := expenseHistogram(transactions, bounds)
+
+	maxCount := 0
+	for _, b := range buckets {
+		if b.Count > maxCount {
+			maxCount = b.Count
+		}
+	}
+
+	const width = 30
+	for _, b := range buckets {
+		label := fmt.Sprintf("%g–%g", b.Low, b.High)
+		if b.High == 0 {
+			label = fmt.Sprintf("%g+", b.Low)
+		}
+		bar := 0
+		if maxCount > 0 {
+			bar = b.Count * width / maxCount
+		}
+		fmt.Printf("  %-10s %-*s %3d  %10.2f\n", label, width, strings.Repeat(glyph("█", "#"), bar), b.Count, b.Total)
+	}
+	fmt.Println()
+}
+
+// TagImpact is a tag's share of total expenses.
+type TagImpact struct {
+	Tag   string
+	Total float64 // absolute expense total
+	Share float64 // fraction of all tagged expense totals
+}
+
+// expenseImpact ranks tags by how much they spend, largest first.
+func expenseImpact(transactions []Transaction) []TagImpact {
+	var expenses []Transaction
+	for _, txn := range transactions {
+		if txn.Amount < 0 {
+			expenses = append(expenses, txn)
+		}
+	}
+
+	var sum float64
+	totals := tagTotals(expenses)
+	for _, total := range totals {
+		sum += -total
+	}
+
+	out := make([]TagImpact, 0, len(totals))
+	for tag, total := range totals {
+		out = append(out, TagImpact{Tag: tag, Total: -total, Share: -total / sum})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Total != out[j].Total {
+			return out[i].Total > out[j].Total
+		}
+		return out[i].Tag < out[j].Tag
+	})
+	return out
+}
+
+// printHighImpactTags lists the biggest expense tags. With --pareto it
+// stops once the listed tags make up that share of spending; otherwise it
+// shows the --top tags.
+func printHighImpactTags(transactions []Transaction) {
+	fmt.Println(glyph("🔥 ", "") + "High-Impact Expense Tags:")
+	impacts := expenseImpact(transactions)
+	base, hasBase := baseTagTotal(os.Stdout, transactions)
+	var cumulative float64
+	for i, ti := range impacts {
+		if pareto <= 0 && topN > 0 && i >= topN {
+			break
+		}
+		cumulative += ti.Share
+		total := colorByMagnitude(fmt.Sprintf("%.2f", ti.Total), -ti.Total, impacts[0].Total)
+		relative := ""
+		if hasBase {
+			relative = fmt.Sprintf("  %.1f%% of %s", ti.Total/base*100, baseTag)
+		}
+		fmt.Printf("  [%s] %s  %5.1f%%  (cumulative %5.1f%%)%s\n", ti.Tag, total, ti.Share*100, cumulative*100, relative)
+		if pareto > 0 && cumulative >= pareto-1e-9 {
+			break
+		}
+	}
+	fmt.Println()
+}
+
+// monthSpan counts calendar months from the earliest to the latest
+// transaction, inclusive, so quiet months still count toward averages.
+func monthSpan(transactions []Transaction) int {
+	if len(transactions) == 0 {
+		return 0
+	}
+	first, last := transactions[0].Date, transactions[0].Date
+	for _, txn := range transactions {
+		if txn.Date.Before(first) {
+			first = txn.Date
+		}
+		if txn.Date.After(last) {
+			last = txn.Date
+		}
+	}
+	return (last.Year()*12 + int(last.Month())) - (first.Year()*12 + int(first.Month())) + 1
+}
+
+func printRunway(transactions []Transaction) {
+	fmt.Println(glyph("🛫 ", "") + "Burn Rate & Runway:")
+	months := monthSpan(transactions)
+	if months == 0 {
+		fmt.Println("  (no transactions)")
+		fmt.Println()
+		return
+	}
+
+	income, expenses := totalAmounts(transactions)
+	monthlyNet := (income + expenses) / float64(months)
+	balance := startingBalance + income + expenses
+
+	fmt.Printf("  Months observed:     %d\n", months)
+	fmt.Printf("  Average monthly net: %.2f\n", monthlyNet)
+	fmt.Printf("  Current balance:     %.2f\n", balance)
+	switch {
+	case monthlyNet >= 0:
+		fmt.Println("  Runway:              infinite (cash-flow positive)")
+	case balance <= 0:
+		fmt.Println("  Runway:              0 months (balance exhausted)")
+	default:
+		fmt.Printf("  Runway:              %.1f months\n", balance/-monthlyNet)
+	}
+	fmt.Println()
+}
+
+// SignSplit separates a tag's income from its expenses, so a tag that
+// carries both is not hidden behind its net total.
+type SignSplit struct {
+	Income  float64
+	Expense float64 // negative
+}
+
+// Category labels a tag by which signs it carries: "Income-only",
+// "Expense-only" or "Mixed".
+func (s SignSplit) Category() string {
+	switch {
+	case s.Income != 0 && s.Expense != 0:
+		return "Mixed"
+	case s.Expense != 0:
+		return "Expense-only"
+	default:
+		return "Income-only"
+	}
+}
+
+func tagSignSplit(transactions []Transaction) map[string]SignSplit {
+	out := map[string]SignSplit{}
+	for _, txn := range transactions {
+		for tag, amount := range tagAmounts(txn) {
+			split := out[tag]
+			if amount >= 0 {
+				split.Income += amount
+			} else {
+				split.Expense += amount
+			}
+			out[tag] = split
+		}
+	}
+	return out
+}
+
+// printPivot cross-tabulates tags against transaction type.
+func printPivot(transactions []Transaction) {
+	fmt.Println(glyph("🧮 ", "") + "Tag × Type:")
+	splits := tagSignSplit(transactions)
+	tags := make([]string, 0, len(splits))
+	width := len("Total")
+	for tag := range splits {
+		tags = append(tags, tag)
+		width = max(width, len(tag))
+	}
+	sort.Strings(tags)
+
+	fmt.Printf("  %-*s %10s %10s %10s\n", width, "Tag", "Income", "Expense", "Total")
+	var income, expense float64
+	for _, tag := range tags {
+		s := splits[tag]
+		fmt.Printf("  %-*s %10.2f %10.2f %10.2f\n", width, tag, s.Income, abs(s.Expense), s.Income+s.Expense)
+		income += s.Income
+		expense += s.Expense
+	}
+	fmt.Printf("  %-*s %10.2f %10.2f %10.2f\n", width, "Total", income, abs(expense), income+expense)
+	fmt.Println()
+}
+
+// newTags lists tags in current that prior never used, largest total first.
+func newTags(current, prior []Transaction) []TagImpact {
+	seen := tagTotals(prior)
+	var out []TagImpact
+	for tag, total := range tagTotals(current) {
+		if _, ok := seen[tag]; !ok && tag != "_untagged_" {
+			out = append(out, TagImpact{Tag: tag, Total: total})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if abs(out[i].Total) != abs(out[j].Total) {
+			return abs(out[i].Total) > abs(out[j].Total)
+		}
+		return out[i].Tag < out[j].Tag
+	})
+	return out
+}
+
+func printNewTags(current, prior []Transaction, priorFile string) {
+	fmt.Printf("%sTags new since %s:\n", glyph("🆕 ", ""), priorFile)
+	tags := newTags(current, prior)
+	if len(tags) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, t := range tags {
+		fmt.Printf("  [%s] %.2f\n", t.Tag, t.Total)
+	}
+	fmt.Println()
+}
+
+// TagSensitivity is how much the projected net moves when one tag's
+// amounts are scaled down and up by the --sensitivity delta.
+type TagSensitivity struct {
+	Tag      string
+	Down, Up float64 // change in net at -delta and +delta
+}
+
+func projectedNet(transactions []Transaction, adjust string) float64 {
+	income, expenses := totalAmounts(buildProjection(transactions, adjust).Projected)
+	return income + expenses
+}
+
+// tagSensitivity runs one single-tag projection per expense tag in each
+// direction, ranked by the size of the effect on net.
+func tagSensitivity(transactions []Transaction, delta float64) []TagSensitivity {
+	baseline := projectedNet(transactions, "")
+	var out []TagSensitivity
+	for _, ti := range expenseImpact(transactions) {
+		if ti.Tag == "_untagged_" {
+			continue
+		}
+		out = append(out, TagSensitivity{
+			Tag:  ti.Tag,
+			Down: projectedNet(transactions, fmt.Sprintf("%s=%g", ti.Tag, -delta)) - baseline,
+			Up:   projectedNet(transactions, fmt.Sprintf("%s=%g", ti.Tag, delta)) - baseline,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return abs(out[i].Up) > abs(out[j].Up) })
+	return out
+}
+
+func printSensitivity(transactions []Transaction, delta float64) {
+	fmt.Printf("%sNet Sensitivity (±%g%% per expense tag):\n", glyph("🎚  ", ""), delta*100)
+	rows := tagSensitivity(transactions, delta)
+	if len(rows) == 0 {
+		fmt.Println("  (no tagged expenses)")
+	}
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r.Tag)+2)
+	}
+	for _, r := range rows {
+		fmt.Printf("  %-*s  -%g%%: %+10.2f   +%g%%: %+10.2f\n", width, "["+r.Tag+"]", delta*100, r.Down, delta*100, r.Up)
+	}
+	fmt.Println()
+}
+
+// printLargestChanges lists the transactions whose projected amount moved
+// furthest from the original, with the rule responsible.
+func printLargestChanges(p Projection) {
+	fmt.Println(glyph("📈 ", "") + "Largest Projected Changes:")
+	order := make([]int, 0, len(p.Original))
+	for i := range p.Original {
+		if abs(p.Projected[i].Amount-p.Original[i].Amount) >= 0.005 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		da := abs(p.Projected[order[a]].Amount - p.Original[order[a]].Amount)
+		db := abs(p.Projected[order[b]].Amount - p.Original[order[b]].Amount)
+		return da > db
+	})
+	if len(order) == 0 {
+		fmt.Println("  (no transactions change)")
+	}
+	for n, i := range order {
+		if topN > 0 && n >= topN {
+			break
+		}
+		o, pr := p.Original[i], p.Projected[i]
+		fmt.Printf("  %s %-24s %10.2f → %10.2f  %+10.2f  %s\n",
+			o.Date.Format("2006-01-02"), o.Description, o.Amount, pr.Amount, pr.Amount-o.Amount, p.Reasons[i])
+	}
+	fmt.Println()
+}
+
+// netContributions splits the projected change in net by tag. Each
+// transaction's change goes to one tag — the one whose --adjust rule
+// applied, else its first tag — so the contributions add up to the total.
+func netContributions(p Projection) (byTag map[string]float64, total float64) {
+	byTag = map[string]float64{}
+	for i, o := range p.Original {
+		delta := p.Projected[i].Amount - o.Amount
+		if delta == 0 {
+			continue
+		}
+		tag := summaryTags(o)[0]
+		for _, t := range o.Tags {
+			if _, ok := p.AdjustMap[t]; ok {
+				tag = t
+				break
+			}
+		}
+		byTag[tag] += delta
+		total += delta
+	}
+	return byTag, total
+}
+
+// printExplainNet shows how each tag drives the change from original to
+// projected net, largest contribution first.
+func printExplainNet(p Projection) {
+	fmt.Println(glyph("🧩 ", "") + "Net Change Explained:")
+	byTag, total := netContributions(p)
+	origIncome, origExpense := totalAmounts(p.Original)
+	origNet := origIncome + origExpense
+	fmt.Printf("  Net %.2f → %.2f (%+.2f)\n", origNet, origNet+total, total)
+
+	tags := make([]string, 0, len(byTag))
+	for tag := range byTag {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		a, b := abs(byTag[tags[i]]), abs(byTag[tags[j]])
+		if a != b {
+			return a > b
+		}
+		return tags[i] < tags[j]
+	})
+	if len(tags) == 0 {
+		fmt.Println("  (no transactions change)")
+	}
+	for _, tag := range tags {
+		share := ""
+		if total != 0 {
+			share = fmt.Sprintf("  %5.1f%%", byTag[tag]/total*100)
+		}
+		fmt.Printf("  [%s] %+.2f%s\n", tag, byTag[tag], share)
+	}
+	fmt.Println()
+}
+
+// printWeekdayWeekend compares expenses on weekdays with weekends. Per-day
+// averages divide by the number of such days in the data's date range, so
+// the five-to-two imbalance does not skew the comparison.
+func printWeekdayWeekend(transactions []Transaction) {
+	fmt.Println(glyph("📅 ", "") + "Weekday vs Weekend Spending:")
+	first, last, ok := dateRange(transactions)
+	if !ok {
+		fmt.Println("  (no transactions)")
+		fmt.Println()
+		return
+	}
+
+	var days [2]int // [weekday, weekend]
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		days[weekendIndex(d)]++
+	}
+	var totals [2]float64
+	var counts [2]int
+	for _, txn := range transactions {
+		if txn.Amount < 0 {
+			i := weekendIndex(txn.Date)
+			totals[i] += -txn.Amount
+			counts[i]++
+		}
+	}
+
+	var perDay [2]float64
+	for i, label := range []string{"Weekday", "Weekend"} {
+		if days[i] > 0 {
+			perDay[i] = totals[i] / float64(days[i])
+		}
+		fmt.Printf("  %-8s %10.2f  %3d txns over %3d days  %8.2f/day\n", label+":", totals[i], counts[i], days[i], perDay[i])
+	}
+	switch {
+	case days[0] == 0 || days[1] == 0:
+		fmt.Println("  Weekend/weekday per-day ratio: n/a (range lacks weekdays or weekend days)")
+	case perDay[0] == 0:
+		fmt.Println("  Weekend/weekday per-day ratio: n/a (no weekday spending)")
+	default:
+		fmt.Printf("  Weekend/weekday per-day ratio: %.2f×\n", perDay[1]/perDay[0])
+	}
+	fmt.Println()
+}
+
+func weekendIndex(d time.Time) int {
+	if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+		return 1
+	}
+	return 0
+}
+
+var percentileLevels = []float64{25, 50, 75, 90}
+
+// percentile interpolates the p-th percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := p / 100 * float64(len(sorted)-1)
+	lo := int(pos)
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (pos-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+func percentileRow(label string, amounts []float64) {
+	sort.Float64s(amounts)
+	fmt.Printf("  %-20s", label)
+	for _, p := range percentileLevels {
+		fmt.Printf(" p%-2g %9.2f", p, percentile(amounts, p))
+	}
+	if len(amounts) < 4 {
+		fmt.Printf("  (only %d expense(s))", len(amounts))
+	}
+	fmt.Println()
+}
+
+// printPercentiles shows the distribution of expense sizes overall and,
+// with --percentiles-by-tag, for each tag.
+func printPercentiles(transactions []Transaction) {
+	fmt.Println(glyph("📐 ", "") + "Expense Percentiles:")
+	var all []float64
+	byTag := map[string][]float64{}
+	for _, txn := range transactions {
+		if txn.Amount >= 0 {
+			continue
+		}
+		all = append(all, -txn.Amount)
+		for _, tag := range summaryTags(txn) {
+			byTag[tag] = append(byTag[tag], -txn.Amount)
+		}
+	}
+	if len(all) == 0 {
+		fmt.Println("  (no expenses)")
+		fmt.Println()
+		return
+	}
+	percentileRow("All", all)
+	if percentilesByTag {
+		tags := make([]string, 0, len(byTag))
+		for tag := range byTag {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			percentileRow("["+tag+"]", byTag[tag])
+		}
+	}
+	fmt.Println()
+}
+
+// TagLifetime is when a tag was first and last used.
+type TagLifetime struct {
+	Tag          string
+	First, Last  time.Time
+	ActiveMonths int
+	Total        float64
+}
+
+// tagTimeline lists every tag's lifetime, most recently seen first so tags
+// that have fallen out of use sink to the bottom.
+func tagTimeline(transactions []Transaction) []TagLifetime {
+	byTag := map[string]*TagLifetime{}
+	months := map[string]map[string]bool{}
+	for _, txn := range transactions {
+		for tag, amount := range tagAmounts(txn) {
+			lt := byTag[tag]
+			if lt == nil {
+				lt = &TagLifetime{Tag: tag, First: txn.Date, Last: txn.Date}
+				byTag[tag] = lt
+				months[tag] = map[string]bool{}
+			}
+			if txn.Date.Before(lt.First) {
+				lt.First = txn.Date
+			}
+			if txn.Date.After(lt.Last) {
+				lt.Last = txn.Date
+			}
+			months[tag][txn.Date.Format("2006-01")] = true
+			lt.Total += amount
+		}
+	}
+	out := make([]TagLifetime, 0, len(byTag))
+	for tag, lt := range byTag {
+		lt.ActiveMonths = len(months[tag])
+		out = append(out, *lt)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Last.Equal(out[j].Last) {
+			return out[i].Last.After(out[j].Last)
+		}
+		return out[i].Tag < out[j].Tag
+	})
+	return out
+}
+
+func printTagTimeline(transactions []Transaction) {
+	fmt.Println(glyph("⏳ ", "") + "Tag Timeline (last seen first):")
+	rows := tagTimeline(transactions)
+	if len(rows) == 0 {
+		fmt.Println("  (no transactions)")
+	}
+	for _, r := range rows {
+		fmt.Printf("  [%s] %s … %s  %d active month(s)  %.2f\n",
+			r.Tag, r.First.Format("2006-01-02"), r.Last.Format("2006-01-02"), r.ActiveMonths, r.Total)
+	}
+	fmt.Println()
+}
+
+// printFocusTags shows count, total, average and a monthly series for
+// each --focus-tags tag, measured over all transactions.
+func printFocusTags(w io.Writer, transactions []Transaction, tags []string) {
+	fmt.Fprintln(w, glyph("🔎 ", "")+"Focus Tags:")
+	groups := groupByPeriod(expandInstallments(transactions), "month")
+	months := sortedPeriods(groups)
+	for _, tag := range tags {
+		var st TagStat
+		for name, s := range tagStats(transactions) {
+			if strings.EqualFold(name, tag) {
+				st.Count += s.Count
+				st.Total += s.Total
+			}
+		}
+		fmt.Fprintf(w, "  [%s] %d transaction(s)  total %.2f  average %.2f\n", tag, st.Count, st.Total, st.Average())
+		if st.Count == 0 || len(months) == 0 {
+			continue
+		}
+		series := make([]float64, len(months))
+		labels := make([]string, len(months))
+		for i, month := range months {
+			for name, total := range tagTotals(groups[month]) {
+				if strings.EqualFold(name, tag) {
+					series[i] += total
+				}
+			}
+			labels[i] = fmt.Sprintf("%s %.2f", month, series[i])
+		}
+		fmt.Fprintf(w, "    %s  %s\n", sparkline(series), strings.Join(labels, ", "))
+	}
+	fmt.Fprintln(w)
+}
+
+// MerchantTotal is the spending under one description prefix.
+type MerchantTotal struct {
+	Merchant string
+	Count    int
+	Total    float64 // absolute expense total
+}
+
+// firstWordMerchant is the lowercased first word of a description, a
+// rough stand-in for the merchant; empty descriptions map to "_blank_".
+func firstWordMerchant(description string) string {
+	if fields := strings.Fields(description); len(fields) > 0 {
+		return strings.ToLower(fields[0])
+	}
+	return "_blank_"
+}
+
+// merchantTotals aggregates expenses by firstWordMerchant, biggest first.
+func merchantTotals(transactions []Transaction) []MerchantTotal {
+	byMerchant := map[string]*MerchantTotal{}
+	for _, txn := range transactions {
+		if txn.Amount >= 0 {
+			continue
+		}
+		m := firstWordMerchant(txn.Description)
+		if byMerchant[m] == nil {
+			byMerchant[m] = &MerchantTotal{Merchant: m}
+		}
+		byMerchant[m].Count++
+		byMerchant[m].Total += -txn.Amount
+	}
+
+	out := make([]MerchantTotal, 0, len(byMerchant))
+	for _, mt := range byMerchant {
+		out = append(out, *mt)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Total != out[j].Total {
+			return out[i].Total > out[j].Total
+		}
+		return out[i].Merchant < out[j].Merchant
+	})
+	return out
+}
+
+// printByFirstWord lists the --top spenders by first description word.
+func printByFirstWord(transactions []Transaction) {
+	fmt.Println(glyph("🏪 ", "") + "Spending by First Word of Description:")
+	merchants := merchantTotals(transactions)
+	if len(merchants) == 0 {
+		fmt.Println("  (no expenses)")
+	}
+	var sum float64
+	for _, mt := range merchants {
+		sum += mt.Total
+	}
+	for i, mt := range merchants {
+		if topN > 0 && i >= topN {
+			fmt.Printf("  … %d more\n", len(merchants)-i)
+			break
+		}
+		fmt.Printf("  %-16s %10.2f  %3d txns  %5.1f%%\n", mt.Merchant, mt.Total, mt.Count, mt.Total/sum*100)
+	}
+	fmt.Println()
+}
+
+// TagCount is how many transactions carry a tag.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// tagCounts counts transactions per lowercased tag, with untagged ones
+// under "_untagged_", sorted by name or, with byCount, most used first.
+func tagCounts(transactions []Transaction, byCount bool) []TagCount {
+	counts := map[string]int{}
+	for _, txn := range transactions {
+		if len(txn.Tags) == 0 {
+			counts["_untagged_"]++
+			continue
+		}
+		seen := map[string]bool{}
+		for _, tag := range txn.Tags {
+			tag = strings.ToLower(tag)
+			if !seen[tag] {
+				seen[tag] = true
+				counts[tag]++
+			}
+		}
+	}
+
+	out := make([]TagCount, 0, len(counts))
+	for tag, n := range counts {
+		out = append(out, TagCount{tag, n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if byCount && out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Tag < out[j].Tag
+	})
+	return out
+}
+
+// printTagList prints one tag per line for --list-tags.
+func printTagList(transactions []Transaction, withCounts bool, order string) error {
+	if order != "name" && order != "count" {
+		return fmt.Errorf("unknown --list-tags-sort %q (supported: name, count)", order)
+	}
+	for _, tc := range tagCounts(transactions, order == "count") {
+		if withCounts {
+			fmt.Printf("%5d  %s\n", tc.Count, tc.Tag)
+		} else {
+			fmt.Println(tc.Tag)
+		}
+	}
+	return nil
+}
+
+// TagGrowth is the least-squares trend of a tag's monthly spending.
+type TagGrowth struct {
+	Tag          string
+	Slope        float64 // change in monthly spending per month
+	ActiveMonths int
+	Monthly      []float64
+}
+
+// minGrowthMonths is the fewest months with spending a tag needs before
+// its trend is ranked.
+const minGrowthMonths = 3
+
+// tagGrowth fits a line to each tag's monthly expense total over every
+// month in the data's range (quiet months count as zero) and returns the
+// ranked tags, fastest growing first, followed by those with too few
+// active months.
+func tagGrowth(transactions []Transaction) (ranked, insufficient []TagGrowth) {
+	first, last, ok := dateRange(transactions)
+	if !ok {
+		return nil, nil
+	}
+	var months []string
+	for m := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(last); m = m.AddDate(0, 1, 0) {
+		months = append(months, m.Format("2006-01"))
+	}
+	groups := groupByPeriod(expandInstallments(transactions), "month")
+
+	series := map[string][]float64{}
+	for i, month := range months {
+		for tag, total := range tagTotals(groups[month]) {
+			if total >= 0 {
+				continue
+			}
+			if series[tag] == nil {
+				series[tag] = make([]float64, len(months))
+			}
+			series[tag][i] = -total
+		}
+	}
+
+	for tag, values := range series {
+		g := TagGrowth{Tag: tag, Monthly: values}
+		for _, v := range values {
+			if v > 0 {
+				g.ActiveMonths++
+			}
+		}
+		if g.ActiveMonths < minGrowthMonths {
+			insufficient = append(insufficient, g)
+			continue
+		}
+		g.Slope = slope(values)
+		ranked = append(ranked, g)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Slope != ranked[j].Slope {
+			return ranked[i].Slope > ranked[j].Slope
+		}
+		return ranked[i].Tag < ranked[j].Tag
+	})
+	sort.Slice(insufficient, func(i, j int) bool { return insufficient[i].Tag < insufficient[j].Tag })
+	return ranked, insufficient
+}
+
+// printGrowth ranks expense tags by how fast their monthly spending grows.
+func printGrowth(transactions []Transaction) {
+	fmt.Println(glyph("📈 ", "") + "Spending Growth by Tag (change per month):")
+	ranked, insufficient := tagGrowth(transactions)
+	if len(ranked) == 0 {
+		fmt.Printf("  (no tag has spending in %d or more months)\n", minGrowthMonths)
+	}
+	for i, g := range ranked {
+		if topN > 0 && i >= topN {
+			break
+		}
+		fmt.Printf("  [%s] %+.2f/month  %s  (%d active months)\n", g.Tag, g.Slope, sparkline(g.Monthly), g.ActiveMonths)
+	}
+	for _, g := range insufficient {
+		fmt.Printf("  [%s] insufficient data (%d of %d months needed)\n", g.Tag, g.ActiveMonths, minGrowthMonths)
+	}
+	fmt.Println()
+}
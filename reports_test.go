@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTagCategoryLabels(t *testing.T) {
+	txns := parseText(t, `# 2024-01-01
++ 3000 Salary [Income]
+- 1200 Rent [Housing]
+- 40 Dinner [Food]
+- 30 Lunch [Food]
++ 100 Refund [Food]
+- 50 Repair [Car]
++ 10 Rebate [Car]
+`)
+	tests := []struct {
+		tag      string
+		category string
+		line     string
+	}{
+		{"Income", "Income-only", "  [Income] Income-only: 3000.00\n"},
+		{"Housing", "Expense-only", "  [Housing] Expense-only: -1200.00\n"},
+		// Net positive but mostly spending: still Mixed, not Income.
+		{"Food", "Mixed", "  [Food] Mixed: 30.00 (+100.00 / -70.00)\n"},
+		{"Car", "Mixed", "  [Car] Mixed: -40.00 (+10.00 / -50.00)\n"},
+	}
+	splits := tagSignSplit(txns)
+	var b strings.Builder
+	printTagSummary(&b, txns)
+	for _, tt := range tests {
+		if got := splits[tt.tag].Category(); got != tt.category {
+			t.Errorf("[%s] labelled %s, want %s", tt.tag, got, tt.category)
+		}
+		if !strings.Contains(b.String(), tt.line) {
+			t.Errorf("tag summary lacks %q:\n%s", tt.line, b.String())
+		}
+	}
+}
+
+func TestNetContributionsSum(t *testing.T) {
+	txns := parseText(t, `# 2024-01-01
++ 3000 Salary [Income]
+- 200 Groceries [Food]
+- 100 Dinner [Work, Dining]
+- 50 Taxi (40)
+- 30 Lunch [Food, Work] (20)
+`)
+	tests := []struct {
+		adjust string
+		byTag  map[string]float64
+	}{
+		{"Food=-0.5,Dining=-0.5", map[string]float64{"Food": 110, "Dining": 50, "_untagged_": 10}},
+		{"Income=0.1", map[string]float64{"Income": 300, "_untagged_": 10, "Food": 10}},
+		{"", map[string]float64{"_untagged_": 10, "Food": 10}},
+	}
+	for _, tt := range tests {
+		p := buildProjection(txns, tt.adjust)
+		byTag, total := netContributions(p)
+
+		origIncome, origExpense := totalAmounts(p.Original)
+		projIncome, projExpense := totalAmounts(p.Projected)
+		change := (projIncome + projExpense) - (origIncome + origExpense)
+		var sum float64
+		for _, v := range byTag {
+			sum += v
+		}
+		if math.Abs(sum-total) > 1e-9 || math.Abs(total-change) > 1e-9 {
+			t.Errorf("%q: contributions sum to %.2f, total %.2f, net changed by %.2f", tt.adjust, sum, total, change)
+		}
+		if len(byTag) != len(tt.byTag) {
+			t.Errorf("%q: contributions %v, want %v", tt.adjust, byTag, tt.byTag)
+		}
+		for tag, want := range tt.byTag {
+			if math.Abs(byTag[tag]-want) > 1e-9 {
+				t.Errorf("%q: [%s] contributed %.2f, want %.2f", tt.adjust, tag, byTag[tag], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(abs(v), 'f', -1, 64)
+}
+
+// formatTransactionLine renders a transaction in the ledger's own syntax:
+// "- 9.49 Coffee [Food, Work] (5.2)".
+func formatTransactionLine(txn Transaction) string {
+	head, tail := transactionLineParts(txn)
+	return strings.TrimSpace(head + " " + tail)
+}
+
+// transactionLineParts splits a formatted line into the part up to the
+// description and the trailing tags/projection, so callers can align them.
+func transactionLineParts(txn Transaction) (head, tail string) {
+	// A multi-component line leads with its first component; the rest
+	// follow the tags.
+	amount, tags := txn.Amount, txn.Tags
+	if len(txn.Components) > 0 {
+		amount, tags = txn.Components[0].Amount, txn.Components[0].Tags
+	}
+	sign := "+"
+	if amount < 0 {
+		sign = "-"
+	}
+	if txn.Status == "pending" {
+		sign += "!"
+	}
+	parts := []string{sign, formatAmount(amount)}
+	if txn.Currency != "" {
+		parts = append(parts, txn.Currency)
+	}
+	if !txn.Date.Equal(dayOf(txn.Date)) {
+		parts = append([]string{txn.Date.Format("15:04")}, parts...)
+	}
+	if txn.Description != "" {
+		parts = append(parts, txn.Description)
+	}
+	if txn.Installments > 1 {
+		parts = append(parts, fmt.Sprintf("installments:%d", txn.Installments))
+	}
+
+	var rest []string
+	if len(tags) > 0 {
+		rest = append(rest, "["+strings.Join(tags, ", ")+"]")
+	}
+	for _, c := range txn.Components[min(1, len(txn.Components)):] {
+		component := []string{"+", formatAmount(c.Amount)}
+		if c.Amount < 0 {
+			component[0] = "-"
+		}
+		if c.Description != "" {
+			component = append(component, c.Description)
+		}
+		if len(c.Tags) > 0 {
+			component = append(component, "["+strings.Join(c.Tags, ", ")+"]")
+		}
+		rest = append(rest, strings.Join(component, " "))
+	}
+	if txn.ProjectedAmount != nil {
+		rest = append(rest, "("+formatAmount(*txn.ProjectedAmount)+")")
+	}
+	return strings.Join(parts, " "), strings.Join(rest, " ")
+}
+
+// formatSource renders transactions back into "# date" sections. A new
+// header starts whenever the date changes, so file order is preserved.
+func formatSource(transactions []Transaction) string {
+	var b strings.Builder
+	for i, txn := range transactions {
+		if i == 0 || !sameDay(txn.Date, transactions[i-1].Date) {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "# %s\n\n", txn.Date.Format("2006-01-02"))
+			writeDayNotes(&b, dayNotes, txn.Date.Format("2006-01-02"))
+		}
+		b.WriteString(formatTransactionLine(txn) + "\n")
+	}
+	return b.String()
+}
+
+// writeDayNotes writes the "> note" lines kept for the date key.
+func writeDayNotes(b *strings.Builder, notes map[string][]string, key string) {
+	for _, note := range notes[key] {
+		b.WriteString("> " + note + "\n")
+	}
+}
+
+func exportSource(transactions []Transaction, filename string) error {
+	return os.WriteFile(filename, []byte(formatSource(transactions)), 0644)
+}
+
+// formatLedger renders transactions in canonical style for --fmt: the
+// original frontmatter block, then one "# date" section per run of same-day
+// transactions in time order (when times are given), then income first,
+// larger amounts first, and tag brackets aligned within the section. A date
+// with notes but no transactions keeps its section in date order.
+func formatLedger(transactions []Transaction, notes map[string][]string, frontmatter []string) string {
+	var b strings.Builder
+	if len(frontmatter) > 0 {
+		b.WriteString(strings.Join(frontmatter, "\n") + "\n\n")
+	}
+
+	days := map[string]bool{}
+	for _, txn := range transactions {
+		days[txn.Date.Format("2006-01-02")] = true
+	}
+	var noteOnly []string
+	for key := range notes {
+		if !days[key] {
+			noteOnly = append(noteOnly, key)
+		}
+	}
+	sort.Strings(noteOnly)
+
+	sections := 0
+	header := func(key string) {
+		if sections > 0 {
+			b.WriteString("\n")
+		}
+		sections++
+		fmt.Fprintf(&b, "# %s\n\n", key)
+		writeDayNotes(&b, notes, key)
+	}
+
+	for start := 0; start < len(transactions); {
+		end := start
+		for end < len(transactions) && sameDay(transactions[end].Date, transactions[start].Date) {
+			end++
+		}
+		day := append([]Transaction(nil), transactions[start:end]...)
+		sort.SliceStable(day, func(i, j int) bool {
+			if !day[i].Date.Equal(day[j].Date) {
+				return day[i].Date.Before(day[j].Date)
+			}
+			if (day[i].Amount >= 0) != (day[j].Amount >= 0) {
+				return day[i].Amount >= 0
+			}
+			return abs(day[i].Amount) > abs(day[j].Amount)
+		})
+
+		width := 0
+		for _, txn := range day {
+			head, _ := transactionLineParts(txn)
+			width = max(width, utf8.RuneCountInString(head))
+		}
+
+		key := day[0].Date.Format("2006-01-02")
+		for len(noteOnly) > 0 && noteOnly[0] < key {
+			header(noteOnly[0])
+			noteOnly = noteOnly[1:]
+		}
+		header(key)
+		for _, txn := range day {
+			head, tail := transactionLineParts(txn)
+			if tail == "" {
+				b.WriteString(head + "\n")
+				continue
+			}
+			fmt.Fprintf(&b, "%-*s %s\n", width, head, tail)
+		}
+		start = end
+	}
+	for _, key := range noteOnly {
+		header(key)
+	}
+	return b.String()
+}
+
+// runFmt formats filename for --fmt: print the result, rewrite the file
+// with --write, or with --check exit non-zero if it isn't formatted.
+func runFmt(filename string, transactions []Transaction, notes map[string][]string) {
+	if len(warnings) > 0 {
+		printWarnings(os.Stderr)
+		fmt.Fprintln(os.Stderr, "  (lines above cannot be represented and will be dropped or changed)")
+	}
+
+	frontmatter, err := rawFrontmatter(filename)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	formatted := formatLedger(transactions, notes, frontmatter)
+
+	switch {
+	case fmtCheck:
+		original, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if string(original) != formatted {
+			fmt.Println(filename + " is not formatted")
+			os.Exit(1)
+		}
+	case fmtWrite:
+		if err := os.WriteFile(filename, []byte(formatted), 0644); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Print(formatted)
+	}
+}
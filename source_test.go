@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatLedgerNotes(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"note with transactions", "# 2024-01-01\n\n> New year\n- 5 Coffee [Food]\n"},
+		{"note-only date between days", "# 2024-01-01\n\n- 5 Coffee [Food]\n\n# 2024-01-02\n\n> Quiet day\n\n# 2024-01-03\n\n- 10 Lunch [Food]\n"},
+		{"note-only date first", "# 2023-12-31\n\n> Last of the year\n\n# 2024-01-01\n\n- 5 Coffee [Food]\n"},
+		{"note-only date last", "# 2024-01-01\n\n- 5 Coffee [Food]\n\n# 2024-01-09\n\n> Trailing note\n"},
+		{"notes only", "# 2024-01-02\n\n> Quiet day\n> Still quiet\n"},
+	}
+	for _, tt := range tests {
+		txns, notes, err := parseLedger(strings.NewReader(tt.text), "test.md")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := formatLedger(txns, notes, nil); got != tt.text {
+			t.Errorf("%s: formatLedger =\n%s\nwant\n%s", tt.name, got, tt.text)
+		}
+	}
+}
+
+func TestParseLedgerKeepsNotesPerParse(t *testing.T) {
+	_, first, err := parseLedger(strings.NewReader("# 2024-01-01\n> Main file\n"), "main.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, second, err := parseLedger(strings.NewReader("# 2024-01-01\n> Prior file\n"), "prior.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := first["2024-01-01"]; len(got) != 1 || got[0] != "Main file" {
+		t.Errorf("first parse notes = %q, want only its own", got)
+	}
+	if got := second["2024-01-01"]; len(got) != 1 || got[0] != "Prior file" {
+		t.Errorf("second parse notes = %q, want only its own", got)
+	}
+	if len(dayNotes) != 0 {
+		t.Errorf("parseLedger filled the global dayNotes: %q", dayNotes)
+	}
+}
+
+func TestFormatTransactionLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"- 10EUR Dinner [Dining]", "- 10 EUR Dinner [Dining]"},
+		{"- 10 EUR", "- 10 EUR"},
+		{"+ 4000 Salary [Income] + 500 Bonus [Bonus]", "+ 4000 Salary [Income] + 500 Bonus [Bonus]"},
+		{"+ 4000 Salary [Income] + 500 Bonus", "+ 4000 Salary [Income] + 500 Bonus"},
+		{"- 30 Dinner [Food] - 2 Tip + 1 Change [Cash]", "- 30 Dinner [Food] - 2 Tip + 1 Change [Cash]"},
+		{"-! 9.49 Coffee [Food, Work] (5.2)", "-! 9.49 Coffee [Food, Work] (5.2)"},
+		{"14:30 - 3 Snack", "14:30 - 3 Snack"},
+	}
+	for _, tt := range tests {
+		txns := parseText(t, "# 2024-01-01\n"+tt.line+"\n")
+		got := formatTransactionLine(txns[0])
+		if got != tt.want {
+			t.Errorf("formatTransactionLine(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+		if again := formatTransactionLine(parseText(t, "# 2024-01-01\n"+got+"\n")[0]); again != got {
+			t.Errorf("%q does not round-trip: %q", got, again)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var descriptionNoise = regexp.MustCompile(`[^a-z ]+`)
+
+// normalizeDescription reduces a description to lowercase words with digits
+// and punctuation stripped, so "NETFLIX.COM 1234" and "Netflix.com" group.
+func normalizeDescription(desc string) string {
+	s := descriptionNoise.ReplaceAllString(strings.ToLower(desc), " ")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Subscription is a recurring monthly expense found by detectSubscriptions.
+type Subscription struct {
+	Description string
+	Count       int
+	AvgAmount   float64 // positive
+	AvgInterval float64 // days
+	Annualized  float64
+}
+
+// detectSubscriptions looks for expenses sharing a normalized description
+// that recur every 25–35 days with every amount within tolerance (a
+// fraction) of their average.
+func detectSubscriptions(transactions []Transaction, tolerance float64) []Subscription {
+	groups := map[string][]Transaction{}
+	for _, txn := range transactions {
+		if txn.Amount >= 0 {
+			continue
+		}
+		key := normalizeDescription(txn.Description)
+		if key == "" {
+			continue
+		}
+		groups[key] = append(groups[key], txn)
+	}
+
+	var out []Subscription
+	for key, txns := range groups {
+		if len(txns) < 2 {
+			continue
+		}
+		sort.SliceStable(txns, func(i, j int) bool {
+			return txns[i].Date.Before(txns[j].Date)
+		})
+
+		var sum float64
+		for _, t := range txns {
+			sum += -t.Amount
+		}
+		avg := sum / float64(len(txns))
+
+		regular := true
+		for i, t := range txns {
+			if abs(-t.Amount-avg) > tolerance*avg {
+				regular = false
+				break
+			}
+			if i > 0 {
+				days := t.Date.Sub(txns[i-1].Date).Hours() / 24
+				if days < 25 || days > 35 {
+					regular = false
+					break
+				}
+			}
+		}
+		if !regular {
+			continue
+		}
+
+		span := txns[len(txns)-1].Date.Sub(txns[0].Date).Hours() / 24
+		out = append(out, Subscription{
+			Description: key,
+			Count:       len(txns),
+			AvgAmount:   avg,
+			AvgInterval: span / float64(len(txns)-1),
+			Annualized:  avg * 12,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Annualized != out[j].Annualized {
+			return out[i].Annualized > out[j].Annualized
+		}
+		return out[i].Description < out[j].Description
+	})
+	return out
+}
+
+func printSubscriptions(transactions []Transaction) {
+	fmt.Println(glyph("🔁 ", "") + "Likely Subscriptions:")
+	subs := detectSubscriptions(transactions, subscriptionTolerance)
+	if len(subs) == 0 {
+		fmt.Println("  (none found)")
+	}
+	for _, s := range subs {
+		fmt.Printf("  %-24s %3d× %8.2f every ~%.0f days  → %.2f/yr\n", s.Description, s.Count, s.AvgAmount, s.AvgInterval, s.Annualized)
+	}
+	fmt.Println()
+}
+
+// parseCadence reads an --expected-cadence such as "25d" or "4w"; a bare
+// number is days.
+func parseCadence(s string) (float64, error) {
+	num, unit := s, 1.0
+	switch {
+	case strings.HasSuffix(s, "d"):
+		num = strings.TrimSuffix(s, "d")
+	case strings.HasSuffix(s, "w"):
+		num, unit = strings.TrimSuffix(s, "w"), 7
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid --expected-cadence %q (use e.g. 25d or 4w)", s)
+	}
+	return n * unit, nil
+}
+
+// CadencePair is two transactions with the same normalized description
+// that are closer together than the expected cadence.
+type CadencePair struct {
+	Description string
+	First, Next Transaction
+	Days        float64
+}
+
+// cadenceViolations pairs up consecutive same-description transactions
+// (income and expenses apart) that are less than days apart, catching
+// double charges whose amounts differ slightly.
+func cadenceViolations(transactions []Transaction, days float64) []CadencePair {
+	groups := map[string][]Transaction{}
+	var keys []string
+	for _, txn := range transactions {
+		desc := normalizeDescription(txn.Description)
+		if desc == "" {
+			continue
+		}
+		key := fmt.Sprintf("%s|%d", desc, signum(txn.Amount))
+		if groups[key] == nil {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], txn)
+	}
+	sort.Strings(keys)
+
+	var out []CadencePair
+	for _, key := range keys {
+		txns := groups[key]
+		sort.SliceStable(txns, func(i, j int) bool {
+			return txns[i].Date.Before(txns[j].Date)
+		})
+		desc, _, _ := strings.Cut(key, "|")
+		for i := 1; i < len(txns); i++ {
+			gap := txns[i].Date.Sub(txns[i-1].Date).Hours() / 24
+			if gap < days {
+				out = append(out, CadencePair{Description: desc, First: txns[i-1], Next: txns[i], Days: gap})
+			}
+		}
+	}
+	return out
+}
+
+// printCadenceViolations reports --expected-cadence clusters. It checks
+// every description left after filtering, so narrow it to bills with
+// --tag or --where.
+func printCadenceViolations(transactions []Transaction, cadence string) error {
+	days, err := parseCadence(cadence)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%sCloser Than Expected (every %g days):\n", glyph("⏱  ", ""), days)
+	pairs := cadenceViolations(transactions, days)
+	if len(pairs) == 0 {
+		fmt.Println("  (none found)")
+	}
+	for _, p := range pairs {
+		fmt.Printf("  %-24s %s %8.2f  and  %s %8.2f  (%.0f days apart)\n", p.Description,
+			dateLabel(p.First.Date), p.First.Amount, dateLabel(p.Next.Date), p.Next.Amount, p.Days)
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagMerge is a group of near-duplicate tags and the canonical spelling
+// they would merge into: the most used variant, ties broken alphabetically.
+type TagMerge struct {
+	Canonical string
+	Variants  []string // every spelling in the group, canonical first
+	Stats     map[string]TagStat
+	Merged    TagStat // a transaction carrying two variants counts once
+}
+
+// levenshtein is the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+// singular strips a common English plural ending, so "groceries" and
+// "grocery" compare as the same word. Short words such as "gas" are left
+// alone.
+func singular(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "xes"):
+		return strings.TrimSuffix(word, "es")
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+// similarTags reports whether two tags look like spellings of the same
+// thing, ignoring case and plural endings. Edit distance only counts for
+// tags long enough that a distance of maxDistance still leaves most of the
+// word intact, so "Car" and "Tax" are not merged.
+func similarTags(a, b string, maxDistance int) bool {
+	la, lb := singular(strings.ToLower(a)), singular(strings.ToLower(b))
+	if la == lb {
+		return true
+	}
+	if min(len([]rune(la)), len([]rune(lb))) <= maxDistance*2 {
+		return false
+	}
+	return levenshtein(la, lb) <= maxDistance
+}
+
+// findTagMerges groups tags that are similar, directly or through a chain
+// of similar tags. Only groups with more than one spelling are returned.
+func findTagMerges(transactions []Transaction, maxDistance int) []TagMerge {
+	stats := map[string]TagStat{}
+	for _, txn := range transactions {
+		for _, tag := range txn.Tags {
+			st := stats[tag]
+			st.Count++
+			st.Total += txn.Amount
+			stats[tag] = st
+		}
+	}
+	tags := make([]string, 0, len(stats))
+	for tag := range stats {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	parent := make([]int, len(tags))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range tags {
+		for j := i + 1; j < len(tags); j++ {
+			if similarTags(tags[i], tags[j], maxDistance) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	groups := map[int][]string{}
+	for i, tag := range tags {
+		groups[find(i)] = append(groups[find(i)], tag)
+	}
+	var out []TagMerge
+	for _, variants := range groups {
+		if len(variants) < 2 {
+			continue
+		}
+		sort.Slice(variants, func(i, j int) bool {
+			ci, cj := stats[variants[i]].Count, stats[variants[j]].Count
+			if ci != cj {
+				return ci > cj
+			}
+			return variants[i] < variants[j]
+		})
+		m := TagMerge{Canonical: variants[0], Variants: variants, Stats: map[string]TagStat{}}
+		for _, v := range variants {
+			m.Stats[v] = stats[v]
+		}
+		set := map[string]bool{}
+		for _, v := range variants {
+			set[strings.ToLower(v)] = true
+		}
+		for _, txn := range transactions {
+			if hasAnyTag(txn, set) {
+				m.Merged.Count++
+				m.Merged.Total += txn.Amount
+			}
+		}
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Canonical < out[j].Canonical })
+	return out
+}
+
+// applyTagMerges rewrites every variant to its canonical tag, dropping
+// tags that become duplicates on the same transaction.
+func applyTagMerges(transactions []Transaction, merges []TagMerge) []Transaction {
+	canonical := map[string]string{}
+	for _, m := range merges {
+		for _, v := range m.Variants {
+			canonical[v] = m.Canonical
+		}
+	}
+	out := make([]Transaction, len(transactions))
+	for i, txn := range transactions {
+		tags := []string{}
+		seen := map[string]bool{}
+		for _, tag := range txn.Tags {
+			if c, ok := canonical[tag]; ok {
+				tag = c
+			}
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+		txn.Tags = tags
+		out[i] = txn
+	}
+	return out
+}
+
+func printTagMerges(merges []TagMerge) {
+	fmt.Println(glyph("🧹 ", "") + "Similar Tags:")
+	if len(merges) == 0 {
+		fmt.Println("  (no near-duplicate tags)")
+	}
+	for _, m := range merges {
+		fmt.Printf("  %s ← %s\n", m.Canonical, strings.Join(m.Variants[1:], ", "))
+		for _, v := range m.Variants {
+			s := m.Stats[v]
+			fmt.Printf("    [%s] %.2f (%d×)\n", v, s.Total, s.Count)
+		}
+		fmt.Printf("    → [%s] %.2f (%d×)\n", m.Canonical, m.Merged.Total, m.Merged.Count)
+	}
+	if len(merges) > 0 && !applyTagMerge {
+		fmt.Println("  (use --apply-tag-merges to merge them)")
+	}
+	fmt.Println()
+}
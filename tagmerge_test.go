@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSimilarTags(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Groceries", "grocery", true},
+		{"Groceries", "Grocerys", true},
+		{"Restaurant", "Resturant", true},
+		{"Taxes", "Tax", true},
+		{"Coffee Shops", "coffee shop", true},
+		{"Car", "Tax", false},
+		{"Gas", "Ga", false},
+		{"Business", "Busines", true},
+		{"Food", "Fuel", false},
+	}
+	for _, tt := range tests {
+		if got := similarTags(tt.a, tt.b, 2); got != tt.want {
+			t.Errorf("similarTags(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFindTagMergesPlural(t *testing.T) {
+	txns := parseText(t, "# 2024-01-01\n- 50 Shop [Groceries]\n- 20 Market [grocery]\n- 30 Shop [Groceries]\n")
+	merges := findTagMerges(txns, 2)
+	if len(merges) != 1 || !reflect.DeepEqual(merges[0].Variants, []string{"Groceries", "grocery"}) {
+		t.Fatalf("merges %+v, want Groceries ← grocery", merges)
+	}
+	if m := merges[0].Merged; m.Count != 3 || m.Total != -100 {
+		t.Errorf("merged %+v, want 3× -100", m)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData is what a --template receives:
+//
+//	.Transactions    []Transaction  filtered transactions (.Date, .Type, .Amount,
+//	                                .Description, .Tags, .ProjectedAmount, .Currency)
+//	.Rows            []SummaryRow   the summary listing (.Date, .Type, .Amount,
+//	                                .Description, .Tags, .Marker, .Balance)
+//	.Income          float64        total of positive amounts
+//	.Expenses        float64        total of negative amounts, as a positive number
+//	.Net             float64        .Income - .Expenses
+//	.StartingBalance float64        --starting-balance (or --prior's closing balance)
+//	.Balance         float64        running balance after the last transaction
+//	.Residual        float64        --round-whole rounding residual; 0 otherwise
+//	.RoundedLines    float64        sum of the rounded line items
+//	.PendingCount    int            number of pending ("!") transactions
+//	.Pending         float64        net of the pending transactions
+//	.Cleared         float64        .Net without the pending transactions
+//	.From, .To       time.Time      earliest and latest transaction dates
+//	.Days            int            days from .From to .To inclusive; 0 if none
+//	.Tags            []TagRow       per-tag stats sorted by tag (.Tag, .Category,
+//	                                .Count, .Total, .Average)
+//	.OutliersSkipped string         why --flag-outliers marked nothing, if it didn't
+//	.Subtotals, .TagSummary, .FocusTags, .Normalized
+//	                 string         the summary's sections as printed; a section
+//	                                whose flag is unset is empty
+//
+// Helper functions: date (YYYY-MM-DD), join, abs, upper, lower, money
+// (honours --round-whole) and glyph (honours --emoji-set).
+type TemplateData struct {
+	Transactions    []Transaction
+	Rows            []SummaryRow
+	Income          float64
+	Expenses        float64
+	Net             float64
+	StartingBalance float64
+	Balance         float64
+	Residual        float64
+	RoundedLines    float64
+	PendingCount    int
+	Pending         float64
+	Cleared         float64
+	From, To        time.Time
+	Days            int
+	Tags            []TagRow
+	OutliersSkipped string
+	Subtotals       string
+	TagSummary      string
+	FocusTags       string
+	Normalized      string
+}
+
+// SummaryRow is one line of the summary listing.
+type SummaryRow struct {
+	Date        string // with the time of day when one was given
+	Type        string
+	Amount      float64
+	Description string
+	Tags        []string
+	Marker      string // the --flag-outliers marker, if any
+	Balance     float64
+}
+
+// TagRow is one tag's entry in TemplateData.Tags.
+type TagRow struct {
+	Tag      string
+	Category string // "Income-only", "Expense-only" or "Mixed"; see SignSplit.Category
+	Count    int
+	Total    float64
+	Average  float64
+}
+
+// defaultSummaryTemplate is the standard summary layout; printSummary
+// renders it, and it is a starting point for custom templates (use
+// --template default).
+const defaultSummaryTemplate = `{{glyph "📊 " ""}}Filtered Cash Flow Summary:
+{{if .Days}}Period: {{date .From}} to {{date .To}} ({{.Days}} days){{else}}Period: (no transactions){{end}}
+{{with .OutliersSkipped}}  (outliers not flagged: {{.}})
+{{end}}{{if not .FocusTags}}{{range .Rows}}{{.Date}} [{{.Type}}] {{money .Amount}} - {{.Description}} {{.Tags}}{{.Marker}}  Balance: {{money .Balance}}
+{{end}}{{.Subtotals}}{{end}}
+Total Income:  {{money .Income}}
+Total Expenses: {{money .Expenses}}
+Net:            {{money .Net}}
+Balance:        {{money .Balance}} (from {{money .StartingBalance}})
+{{if .Residual}}Rounding:       {{printf "%+.0f" .Residual}} (rounded lines sum to {{money .RoundedLines}})
+{{end}}{{if .PendingCount}}Pending ({{.PendingCount}}):    {{money .Pending}}
+Cleared net:    {{money .Cleared}}
+{{end}}
+{{with .FocusTags}}{{.}}{{else}}{{.TagSummary}}
+{{.Normalized}}{{end}}`
+
+func buildTemplateData(transactions []Transaction) TemplateData {
+	income, expenses := totalAmounts(transactions)
+	data := TemplateData{
+		Transactions:    transactions,
+		Income:          income,
+		Expenses:        abs(expenses),
+		Net:             income + expenses,
+		StartingBalance: startingBalance,
+		Balance:         startingBalance,
+		Residual:        roundingResidual(transactions),
+	}
+	data.RoundedLines = data.Net + data.Residual
+	if first, last, ok := dateRange(transactions); ok {
+		data.From, data.To = first, last
+		data.Days = int(last.Sub(first).Hours()/24) + 1
+	}
+
+	var averages map[string]float64
+	if flagOutliers {
+		if note := dataShortfall(transactions); note != "" {
+			data.OutliersSkipped = note
+		} else {
+			averages = averageExpenseByTag(transactions)
+		}
+	}
+	for _, txn := range transactions {
+		data.Balance += txn.Amount
+		marker := ""
+		if flagOutliers && isOutlier(txn, averages) {
+			marker = glyph(" ⚠", " !")
+		}
+		data.Rows = append(data.Rows, SummaryRow{
+			Date:        dateLabel(txn.Date),
+			Type:        txn.Type,
+			Amount:      txn.Amount,
+			Description: txn.Description,
+			Tags:        txn.Tags,
+			Marker:      marker,
+			Balance:     data.Balance,
+		})
+		if txn.Status == "pending" {
+			data.Pending += txn.Amount
+			data.PendingCount++
+		}
+	}
+	data.Cleared = data.Net - data.Pending
+
+	splits := tagSignSplit(transactions)
+	for tag, st := range tagStats(transactions) {
+		data.Tags = append(data.Tags, TagRow{
+			Tag:      tag,
+			Category: splits[tag].Category(),
+			Count:    st.Count,
+			Total:    st.Total,
+			Average:  st.Average(),
+		})
+	}
+	sort.Slice(data.Tags, func(i, j int) bool {
+		return data.Tags[i].Tag < data.Tags[j].Tag
+	})
+
+	var b strings.Builder
+	if groupBy != "" {
+		printPeriodSubtotals(&b, transactions, groupBy)
+		data.Subtotals = b.String()
+		b.Reset()
+	}
+	if focusTags != "" {
+		printFocusTags(&b, transactions, splitList(focusTags))
+		data.FocusTags = b.String()
+		b.Reset()
+	}
+	printTagSummary(&b, transactions)
+	data.TagSummary = b.String()
+	b.Reset()
+	if normalizeTo != "" {
+		printNormalized(&b, transactions, normalizeTo)
+		data.Normalized = b.String()
+	}
+	return data
+}
+
+// printTemplateSummary renders the summary through the --template file, or
+// the built-in layout when the flag is "default".
+func printTemplateSummary(transactions []Transaction, filename string) error {
+	text := defaultSummaryTemplate
+	if filename != "default" {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		text = string(b)
+	}
+
+	return renderSummary(os.Stdout, text, transactions)
+}
+
+// renderSummary executes template text over transactions' TemplateData.
+func renderSummary(w io.Writer, text string, transactions []Transaction) error {
+	tmpl, err := template.New("summary").Funcs(template.FuncMap{
+		"date":  func(t time.Time) string { return t.Format("2006-01-02") },
+		"join":  strings.Join,
+		"abs":   abs,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"money": money,
+		"glyph": glyph,
+	}).Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, buildTemplateData(transactions))
+}
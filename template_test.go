@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultSummaryTemplate(t *testing.T) {
+	defer func(v bool) { roundWhole = v }(roundWhole)
+	defer func(v float64) { startingBalance = v }(startingBalance)
+
+	ledger := "# 2024-01-01\n+ 1000 Salary [Income]\n-! 45.5 Groceries [Food]\n14:30 - 9.49 Coffee [Food]\n"
+	tests := []struct {
+		name   string
+		ledger string // defaults to ledger
+		round  bool
+		start  float64
+		want   []string
+	}{
+		{"running balance", "", false, 100, []string{
+			"2024-01-01 [income] 1000.00 - Salary [Income]  Balance: 1100.00\n",
+			"2024-01-01 [expense] -45.50 - Groceries [Food]  Balance: 1054.50\n",
+			"2024-01-01 14:30 [expense] -9.49 - Coffee [Food]  Balance: 1045.01\n",
+			"\nTotal Income:  1000.00\nTotal Expenses: 54.99\nNet:            945.01\nBalance:        1045.01 (from 100.00)\n",
+		}},
+		{"pending and cleared", "", false, 0, []string{
+			"Pending (1):    -45.50\nCleared net:    990.51\n\n",
+		}},
+		{"whole units", "", true, 0, []string{
+			"2024-01-01 [expense] -46 - Groceries [Food]  Balance: 955\n",
+			"Net:            945\n",
+			"Pending (1):    -46\nCleared net:    991\n",
+		}},
+		{"rounding residual", "# 2024-01-01\n- 0.5 Gum [Food]\n- 0.5 Mints [Food]\n", true, 0, []string{
+			"Net:            -1\n",
+			"Rounding:       -1 (rounded lines sum to -2)\n",
+		}},
+		{"tag totals", "", false, 0, []string{
+			"Totals by Tag:\n  [Food] Expense-only: -54.99\n  [Income] Income-only: 1000.00\n",
+		}},
+	}
+	for _, tt := range tests {
+		roundWhole, startingBalance = tt.round, tt.start
+		text := ledger
+		if tt.ledger != "" {
+			text = tt.ledger
+		}
+		var b strings.Builder
+		if err := renderSummary(&b, defaultSummaryTemplate, parseText(t, text)); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(b.String(), want) {
+				t.Errorf("%s: summary lacks %q:\n%s", tt.name, want, b.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// stringList is a flag that may be given more than once.
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ", ") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// parseWhatIf turns --what-if values into transactions using the ledger's
+// own line syntax. A value may start with a date; otherwise it is dated
+// today.
+func parseWhatIf(lines []string) ([]Transaction, error) {
+	var out []Transaction
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		date := time.Now().Format("2006-01-02")
+		if first, rest, ok := strings.Cut(line, " "); ok {
+			if _, err := time.Parse("2006-01-02", first); err == nil {
+				date, line = first, strings.TrimSpace(rest)
+			}
+		}
+		before := len(warnings)
+		txns, _, err := parseLedger(strings.NewReader("# "+date+"\n"+line+"\n"), "--what-if")
+		if err != nil {
+			return nil, err
+		}
+		if len(txns) != 1 {
+			warnings = warnings[:before]
+			return nil, fmt.Errorf("cannot parse %q as a transaction line", line)
+		}
+		out = append(out, txns[0])
+	}
+	return out, nil
+}
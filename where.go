@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A --where expression filters transactions, e.g.
+//
+//	tag=Food and amount>50 and date>=2024-01-01
+//	not (type=income or description~refund)
+//
+// Fields: tag (matches any of the transaction's tags), type, description,
+// amount (the magnitude, so expenses compare as positive numbers) and date.
+// Operators: = != < <= > >= and ~ (contains, for text fields). Text
+// comparisons ignore case. Values with spaces can be double-quoted.
+
+type whereToken struct {
+	text string
+	col  int
+	kind byte // 'w' word, 's' quoted string, 'o' operator, '(' or ')'
+}
+
+func tokenizeWhere(s string) ([]whereToken, error) {
+	var tokens []whereToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, whereToken{string(c), i + 1, c})
+			i++
+		case c == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at column %d", i+1)
+			}
+			tokens = append(tokens, whereToken{s[i+1 : i+1+end], i + 1, 's'})
+			i += end + 2
+		case strings.ContainsRune("=!<>~", rune(c)):
+			op := string(c)
+			if i+1 < len(s) && s[i+1] == '=' && c != '=' && c != '~' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected %q at column %d", op, i+1)
+			}
+			tokens = append(tokens, whereToken{op, i + 1, 'o'})
+			i += len(op)
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t()\"=!<>~", rune(s[i])) {
+				i++
+			}
+			tokens = append(tokens, whereToken{s[start:i], start + 1, 'w'})
+		}
+	}
+	return tokens, nil
+}
+
+type whereParser struct {
+	tokens []whereToken
+	pos    int
+}
+
+func (p *whereParser) peek() *whereToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *whereParser) keyword(kw string) bool {
+	if t := p.peek(); t != nil && t.kind == 'w' && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *whereParser) errorAt(t *whereToken, msg string) error {
+	if t == nil {
+		return fmt.Errorf("%s at end of expression", msg)
+	}
+	return fmt.Errorf("%s: %q at column %d", msg, t.text, t.col)
+}
+
+func (p *whereParser) parseOr() (func(Transaction) bool, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t Transaction) bool { return l(t) || right(t) }
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseAnd() (func(Transaction) bool, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t Transaction) bool { return l(t) && right(t) }
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseNot() (func(Transaction) bool, error) {
+	if p.keyword("not") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(t Transaction) bool { return !inner(t) }, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *whereParser) parsePrimary() (func(Transaction) bool, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, p.errorAt(nil, "expected a condition")
+	}
+	if t.kind == '(' {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.peek(); c == nil || c.kind != ')' {
+			return nil, p.errorAt(c, "expected )")
+		}
+		p.pos++
+		return inner, nil
+	}
+	if t.kind != 'w' {
+		return nil, p.errorAt(t, "expected a field name")
+	}
+	field := strings.ToLower(t.text)
+	p.pos++
+
+	opTok := p.peek()
+	if opTok == nil || opTok.kind != 'o' {
+		return nil, p.errorAt(opTok, "expected an operator")
+	}
+	p.pos++
+	valTok := p.peek()
+	if valTok == nil || (valTok.kind != 'w' && valTok.kind != 's') {
+		return nil, p.errorAt(valTok, "expected a value")
+	}
+	p.pos++
+	op, val := opTok.text, valTok.text
+
+	switch field {
+	case "tag":
+		if op != "=" && op != "!=" && op != "~" {
+			return nil, p.errorAt(opTok, "tag supports =, != and ~")
+		}
+		return func(t Transaction) bool {
+			for _, tag := range t.Tags {
+				if textMatch(tag, "=", val) || (op == "~" && textMatch(tag, "~", val)) {
+					return op != "!="
+				}
+			}
+			return op == "!="
+		}, nil
+	case "type", "description":
+		if op != "=" && op != "!=" && op != "~" {
+			return nil, p.errorAt(opTok, field+" supports =, != and ~")
+		}
+		return func(t Transaction) bool {
+			s := t.Type
+			if field == "description" {
+				s = t.Description
+			}
+			return textMatch(s, op, val)
+		}, nil
+	case "amount":
+		want, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, p.errorAt(valTok, "expected a number")
+		}
+		cmp, err := p.comparison(opTok)
+		if err != nil {
+			return nil, err
+		}
+		return func(t Transaction) bool { return cmp(compareFloat(abs(t.Amount), want)) }, nil
+	case "date":
+		want, err := time.Parse("2006-01-02", val)
+		if err != nil {
+			return nil, p.errorAt(valTok, "expected a date YYYY-MM-DD")
+		}
+		cmp, err := p.comparison(opTok)
+		if err != nil {
+			return nil, err
+		}
+		return func(t Transaction) bool { return cmp(dayOf(t.Date).Compare(want)) }, nil
+	default:
+		return nil, p.errorAt(t, "unknown field")
+	}
+}
+
+// comparison turns an ordering operator into a test on a -1/0/1 result.
+func (p *whereParser) comparison(opTok *whereToken) (func(int) bool, error) {
+	switch opTok.text {
+	case "=":
+		return func(c int) bool { return c == 0 }, nil
+	case "!=":
+		return func(c int) bool { return c != 0 }, nil
+	case "<":
+		return func(c int) bool { return c < 0 }, nil
+	case "<=":
+		return func(c int) bool { return c <= 0 }, nil
+	case ">":
+		return func(c int) bool { return c > 0 }, nil
+	case ">=":
+		return func(c int) bool { return c >= 0 }, nil
+	}
+	return nil, p.errorAt(opTok, "unsupported operator")
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func textMatch(s, op, val string) bool {
+	switch op {
+	case "=":
+		return strings.EqualFold(s, val)
+	case "!=":
+		return !strings.EqualFold(s, val)
+	case "~":
+		return strings.Contains(strings.ToLower(s), strings.ToLower(val))
+	}
+	return false
+}
+
+// compileWhere parses a --where expression into a predicate.
+func compileWhere(expr string) (func(Transaction) bool, error) {
+	tokens, err := tokenizeWhere(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &whereParser{tokens: tokens}
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t != nil {
+		return nil, p.errorAt(t, "unexpected token")
+	}
+	return pred, nil
+}